@@ -1,79 +1,193 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"pr-reviewer/internal/config"
+	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/handlers"
-	"pr-reviewer/internal/repository/postgres"
+	"pr-reviewer/internal/health"
+	"pr-reviewer/internal/jwtauth"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/metrics"
 	"pr-reviewer/internal/service"
+	"pr-reviewer/internal/tracing"
+	"pr-reviewer/internal/worker"
+	"sync"
+	"syscall"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 )
 
 func main() {
-	logger := initLogger()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger, err := logging.New(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		zap.Must(zap.NewDevelopment()).Fatal("Failed to initialize logger", zap.Error(err))
+	}
 	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
 
-	cfg, err := config.Load()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.File)
 	if err != nil {
-		logger.Fatal("Failed to load config", zap.Error(err))
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
 	}
+	defer shutdownTracing(context.Background())
 
-	db, err := connect(cfg.DSN())
+	store, err := openStorage(context.Background(), cfg, logger)
 	if err != nil {
-		logger.Fatal("Failed to initialize database", zap.Error(err))
+		logger.Fatal("Failed to initialize storage", zap.Error(err))
+	}
+	defer store.Close()
+
+	// Логирование идёт после otelgin, чтобы в логгер запроса попал trace_id
+	r := gin.New()
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+	r.Use(logging.Middleware(logger))
+	r.Use(logging.Recovery())
+	r.Use(metrics.Middleware())
+
+	selectors, err := service.NewTeamSelectors(cfg.Assignment.Strategy, cfg.Assignment.TeamStrategies)
+	if err != nil {
+		logger.Fatal("Invalid reviewer strategy config", zap.Error(err))
+	}
+	svc := service.NewService(store.db, store.teams, store.users, store.prs, store.owners, store.hooks, store.tokens, selectors, domain.TeamSettings{
+		ReviewerCount: cfg.Assignment.ReviewerCount,
+		MinReviewers:  cfg.Assignment.MinReviewers,
+		SLAHours:      &cfg.Assignment.SLAHours,
+		SLAAction:     domain.SLAAction(cfg.Assignment.SLAAction),
+	})
+	metrics.RegisterDB(store.sqlDB, cfg.Storage, svc.OpenReviewsByUser)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	slaWorker := worker.NewSLAWorker(svc, cfg.Workers.SLACheckInterval, logger)
+	webhookWorker := worker.NewWebhookWorker(svc, cfg.Workers.WebhookDispatchInterval, cfg.Workers.WebhookTimeout, cfg.Workers.WebhookMaxAttempts, logger)
+	workers.Go(func() { slaWorker.Run(workersCtx) })
+	workers.Go(func() { webhookWorker.Run(workersCtx) })
+
+	var verifier *jwtauth.Verifier
+	if cfg.Auth.JWT.JWKS != "" {
+		verifier, err = jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+			JWKS:      cfg.Auth.JWT.JWKS,
+			Refresh:   cfg.Auth.JWT.JWKSRefresh,
+			Issuer:    cfg.Auth.JWT.Issuer,
+			Audience:  cfg.Auth.JWT.Audience,
+			UserClaim: cfg.Auth.JWT.UserClaim,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize JWT validation", zap.Error(err))
+		}
+		logger.Info("JWT validation enabled", zap.String("jwks", cfg.Auth.JWT.JWKS))
+	}
+
+	handler := handlers.NewHandler(svc, handlers.Options{
+		GitHubWebhookSecret: cfg.Integrations.GitHubWebhookSecret,
+		GitLabWebhookToken:  cfg.Integrations.GitLabWebhookToken,
+		AuthEnabled:         cfg.Auth.Enabled,
+		AdminToken:          cfg.Auth.AdminToken,
+		JWT:                 verifier,
+	})
+	if !cfg.Auth.Enabled {
+		logger.Warn("API authentication is disabled, all routes are anonymous")
 	}
-	defer db.Close()
-	logger.Info("Succesfully connected to database",
-		zap.String("host", cfg.DBHost),
-		zap.String("db", cfg.DBName),
-	)
-
-	r := gin.Default()
-
-	repoTeams := postgres.NewTeamRepo()
-	repoUsers := postgres.NewUserRepo()
-	repoPR := postgres.NewPRRepo()
-	svc := service.NewService(db, repoTeams, repoUsers, repoPR)
-	handler := handlers.NewHandler(svc)
 	handler.InitRoutes(r)
 
+	checker := health.NewChecker(store.sqlDB, store.schemaVersion)
+	r.GET("/healthz", checker.Liveness)
+	r.GET("/readyz", checker.Readiness)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 	r.GET("ping", func(c *gin.Context) {
-		c.JSON(200, gin.H{
+		if err := checker.PingDB(c.Request.Context()); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "error",
+				"db":     err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 			"db":     "connected",
 		})
 	})
-	logger.Info("Starting server", zap.String("port", cfg.ServerAddress))
 
-	if err := r.Run(cfg.ServerAddress); err != nil {
-		logger.Fatal("Server failed", zap.Error(err))
+	srv := &http.Server{
+		Addr:              cfg.HTTP.Address(),
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTP.ReadTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
-}
 
-func initLogger() *zap.Logger {
-	logger, err := zap.NewDevelopment()
-	if err != nil {
-		panic(err)
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting server", zap.String("port", cfg.HTTP.Address()))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		logger.Error("Server failed", zap.Error(err))
+	case <-ctx.Done():
+		logger.Info("Shutting down")
 	}
-	zap.ReplaceGlobals(logger)
 
-	return logger
-}
+	// Сначала перестаём считаться готовыми и дожидаемся текущих запросов,
+	// затем останавливаем воркеры; БД закрывается отложенным store.Close
+	checker.SetDraining()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
 
-func connect(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database driver: %w", err)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to drain HTTP requests", zap.Error(err))
 	}
 
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	stopWorkers()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-shutdownCtx.Done():
+		logger.Error("Background workers did not stop in time")
 	}
 
-	return db, nil
+	logger.Info("Server stopped")
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"pr-reviewer/internal/config"
+	"pr-reviewer/internal/schema"
+	"strconv"
+	"strings"
+)
+
+const migrateUsage = `usage: pr_reviewer_service migrate <command> [flags]
+
+commands:
+  up             apply all pending migrations
+  down [N]       roll back N migrations (default 1)
+  status         print current and latest schema version
+  force VERSION  set schema version and clear the dirty flag without running migrations
+
+flags are the same as for the service itself, e.g. --config or --db-host`
+
+// runMigrate выполняет подкоманду migrate и возвращает код выхода.
+func runMigrate(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	action, rest := args[0], args[1:]
+
+	// Позиционный аргумент команды отделяем до разбора флагов:
+	// config.Load не принимает лишних аргументов
+	var number int
+	switch action {
+	case "up", "status":
+	case "down":
+		number = 1
+		if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+			n, err := strconv.Atoi(rest[0])
+			if err != nil || n <= 0 {
+				fmt.Fprintf(os.Stderr, "down: expected a positive number of steps, got %q\n", rest[0])
+				return 2
+			}
+			number, rest = n, rest[1:]
+		}
+	case "force":
+		if len(rest) == 0 || strings.HasPrefix(rest[0], "-") {
+			fmt.Fprintln(os.Stderr, "force: VERSION is required")
+			return 2
+		}
+		n, err := strconv.Atoi(rest[0])
+		if err != nil || n < 0 {
+			fmt.Fprintf(os.Stderr, "force: expected a version number, got %q\n", rest[0])
+			return 2
+		}
+		number, rest = n, rest[1:]
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s\n", action, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(rest)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if cfg.Storage != config.StoragePostgres {
+		fmt.Fprintf(os.Stderr, "migrations apply only to %s storage, got %s\n", config.StoragePostgres, cfg.Storage)
+		return 2
+	}
+
+	mg, err := schema.New(cfg.DSN())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer mg.Close()
+
+	switch action {
+	case "up":
+		err = mg.Up()
+	case "down":
+		err = mg.Down(number)
+	case "force":
+		err = mg.Force(number)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", action, err)
+		return 1
+	}
+
+	st, err := mg.Status()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read schema version: %v\n", err)
+		return 1
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", st.Version, st.Dirty, st.Latest)
+	return 0
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pr-reviewer/internal/config"
+	"pr-reviewer/internal/repository"
+	"pr-reviewer/internal/repository/memory"
+	"pr-reviewer/internal/repository/postgres"
+	"pr-reviewer/internal/repository/sqlite"
+	"pr-reviewer/internal/schema"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+// storage — выбранное конфигурацией хранилище и его репозитории.
+type storage struct {
+	db repository.DB
+	// sqlDB — пул соединений с БД, nil для хранилища в памяти
+	sqlDB *sql.DB
+	// schemaVersion — версия миграций PostgreSQL, которую проверяет /readyz;
+	// 0 — не проверять (SQLite применяет схему при открытии)
+	schemaVersion uint
+
+	teams  repository.TeamRepository
+	users  repository.UserRepository
+	prs    repository.PullRequestRepository
+	owners repository.CodeOwnersRepository
+	hooks  repository.WebhookRepository
+	tokens repository.TokenRepository
+}
+
+func openStorage(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*storage, error) {
+	if cfg.Storage == config.StorageMemory {
+		logger.Warn("Using in-memory storage, all data will be lost on shutdown")
+		return &storage{
+			db:     memory.NewStore(),
+			teams:  memory.NewTeamRepo(),
+			users:  memory.NewUserRepo(),
+			prs:    memory.NewPRRepo(),
+			owners: memory.NewCodeOwnersRepo(),
+			hooks:  memory.NewWebhookRepo(),
+			tokens: memory.NewTokenRepo(),
+		}, nil
+	}
+
+	if cfg.Storage == config.StorageSQLite {
+		db, err := sqlite.Open(ctx, cfg.SQLite.Path)
+		if err != nil {
+			return nil, err
+		}
+		version, err := db.SchemaVersion(ctx)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to read sqlite schema version: %w", err)
+		}
+		logger.Info("Opened SQLite database", zap.String("path", cfg.SQLite.Path), zap.Int("schema_version", version))
+
+		return &storage{
+			db:     db,
+			sqlDB:  db.DB,
+			teams:  sqlite.NewTeamRepo(),
+			users:  sqlite.NewUserRepo(),
+			prs:    sqlite.NewPRRepo(),
+			owners: sqlite.NewCodeOwnersRepo(),
+			hooks:  sqlite.NewWebhookRepo(),
+			tokens: sqlite.NewTokenRepo(),
+		}, nil
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := schema.AutoMigrate(ctx, cfg.DSN(), logger); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	db, err := connect(cfg.DSN(), cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Succesfully connected to database",
+		zap.String("host", cfg.Database.Host),
+		zap.String("db", cfg.Database.Name),
+		zap.String("sslmode", cfg.Database.SSLMode),
+	)
+
+	return &storage{
+		db:            postgres.NewDB(db),
+		sqlDB:         db,
+		schemaVersion: schema.LatestVersion(),
+		teams:         postgres.NewTeamRepo(),
+		users:         postgres.NewUserRepo(),
+		prs:           postgres.NewPRRepo(),
+		owners:        postgres.NewCodeOwnersRepo(),
+		hooks:         postgres.NewWebhookRepo(),
+		tokens:        postgres.NewTokenRepo(),
+	}, nil
+}
+
+func (s *storage) Close() error {
+	if s.sqlDB == nil {
+		return nil
+	}
+	return s.sqlDB.Close()
+}
+
+func connect(dsn string, cfg config.DatabaseConfig) (*sql.DB, error) {
+	// Каждый запрос к БД, в том числе внутри транзакции, попадает в трассировку
+	db, err := otelsql.Open("pgx", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database driver: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
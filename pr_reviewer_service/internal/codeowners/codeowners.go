@@ -0,0 +1,157 @@
+// Package codeowners разбирает файлы в формате GitHub CODEOWNERS
+// и сопоставляет пути с правилами владения.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"pr-reviewer/internal/domain"
+)
+
+// Parse читает правила в синтаксисе CODEOWNERS. Владелец "@org/team"
+// трактуется как команда team, "@id" — как пользователь с этим id.
+func Parse(r io.Reader) ([]domain.OwnershipRule, error) {
+	var rules []domain.OwnershipRule
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern := fields[0]
+		if _, err := compile(pattern); err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %w", lineNo, pattern, err)
+		}
+
+		rule := domain.OwnershipRule{Pattern: pattern, Owners: []domain.Owner{}}
+		for _, field := range fields[1:] {
+			owner, err := parseOwner(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func parseOwner(field string) (domain.Owner, error) {
+	name, ok := strings.CutPrefix(field, "@")
+	if !ok || name == "" {
+		return domain.Owner{}, fmt.Errorf("unsupported owner %q: expected @user or @org/team", field)
+	}
+
+	if _, team, isTeam := strings.Cut(name, "/"); isTeam {
+		if team == "" {
+			return domain.Owner{}, fmt.Errorf("unsupported owner %q: empty team name", field)
+		}
+		return domain.Owner{Type: domain.OwnerTeam, ID: team}, nil
+	}
+	return domain.Owner{Type: domain.OwnerUser, ID: name}, nil
+}
+
+// OwnersOf возвращает владельцев пути по последнему подходящему правилу,
+// как это делает GitHub. Если ни одно правило не подошло, возвращается nil.
+func OwnersOf(rules []domain.OwnershipRule, path string) []domain.Owner {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(rules) - 1; i >= 0; i-- {
+		if Match(rules[i].Pattern, path) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// Match сообщает, подходит ли путь под шаблон CODEOWNERS.
+func Match(pattern, path string) bool {
+	re, err := compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// compiled хранит регулярные выражения уже разобранных шаблонов: правила
+// читаются из БД при каждом назначении, а набор шаблонов меняется только импортом.
+var compiled sync.Map
+
+// compile возвращает регулярное выражение шаблона, разбирая его только при первом обращении.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := compiled.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := translate(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Store(pattern, re)
+	return re, nil
+}
+
+// translate переводит gitignore-подобный шаблон в регулярное выражение.
+func translate(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	// "docs/*" и "/*" не распространяются на вложенные каталоги
+	filesOnly := strings.HasSuffix(pattern, "/*")
+	p := strings.TrimSuffix(pattern, "/")
+	// Шаблон со слэшем в начале или середине привязан к корню репозитория
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case filesOnly:
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,138 @@
+package codeowners
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"pr-reviewer/internal/domain"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "main.go", true},
+		{"*", "cmd/main.go", true},
+		{"*.go", "internal/service/pr.go", true},
+		{"*.go", "go.mod", false},
+		{"?.md", "a.md", true},
+		{"?.md", "ab.md", false},
+		{"?.md", "docs/a.md", true},
+
+		{"**/x", "x", true},
+		{"**/x", "a/b/x", true},
+		{"**/x", "a/b/x/y.go", true},
+		{"**/x", "a/bx", false},
+		{"a/**", "a/b", true},
+		{"a/**", "a/b/c.go", true},
+		{"a/**", "b/a/c.go", false},
+
+		// Слэш в конце — каталог на любой глубине, в начале — только от корня
+		{"docs/", "docs/a.md", true},
+		{"docs/", "docs/guide/a.md", true},
+		{"docs/", "src/docs/a.md", true},
+		{"docs/", "docs", false},
+		{"/docs/", "docs/guide/a.md", true},
+		{"/docs/", "src/docs/a.md", false},
+
+		// "docs/*" не распространяется на вложенные каталоги
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/guide/a.md", false},
+		{"docs/*", "src/docs/a.md", false},
+		{"/*", "Makefile", true},
+		{"/*", "cmd/main.go", false},
+
+		{"/build/logs", "build/logs/x.log", true},
+		{"/build/logs", "src/build/logs/x.log", false},
+		{"build", "src/build/x", true},
+		{"apps/api", "apps/api/main.go", true},
+		{"apps/api", "x/apps/api/main.go", false},
+
+		// Ведущий слэш в пути не влияет на сопоставление
+		{"/docs/", "/docs/a.md", true},
+		{"*.go", "/main.go", true},
+
+		{"a.b", "axb", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOwnersOfLastMatchWins(t *testing.T) {
+	rules, err := Parse(strings.NewReader(`
+# владельцы по умолчанию
+*           @u1
+/docs/      @org/docs
+docs/api.md @u2 @org/api
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []domain.Owner
+	}{
+		{"main.go", []domain.Owner{{Type: domain.OwnerUser, ID: "u1"}}},
+		{"docs/guide.md", []domain.Owner{{Type: domain.OwnerTeam, ID: "docs"}}},
+		{"/docs/guide.md", []domain.Owner{{Type: domain.OwnerTeam, ID: "docs"}}},
+		{"docs/api.md", []domain.Owner{{Type: domain.OwnerUser, ID: "u2"}, {Type: domain.OwnerTeam, ID: "api"}}},
+	}
+	for _, tt := range tests {
+		if got := OwnersOf(rules, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("OwnersOf(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if got := OwnersOf(rules[1:], "main.go"); got != nil {
+		t.Errorf("OwnersOf without a matching rule = %v, want nil", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	rules, err := Parse(strings.NewReader("\n  # комментарий\n*.go @u1 # владелец Go-кода\n/vendor/\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.OwnershipRule{
+		{Pattern: "*.go", Owners: []domain.Owner{{Type: domain.OwnerUser, ID: "u1"}}},
+		// Правило без владельцев снимает владение с подходящих путей
+		{Pattern: "/vendor/", Owners: []domain.Owner{}},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("Parse = %v, want %v", rules, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"owner without @", "*.go u1", `line 1: unsupported owner "u1"`},
+		{"bare @", "*.go @", `line 1: unsupported owner "@"`},
+		{"empty team", "*.go @org/", `line 1: unsupported owner "@org/": empty team name`},
+		{"email owner", "*.go dev@example.com", `line 1: unsupported owner "dev@example.com"`},
+		{"empty pattern", "*.go @u1\n/ @u2", `line 2: invalid pattern "/"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
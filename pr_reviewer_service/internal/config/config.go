@@ -1,44 +1,303 @@
+// Package config собирает конфигурацию сервиса из значений по умолчанию,
+// YAML-файла, переменных окружения и флагов командной строки — в порядке
+// возрастания приоритета.
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	ServerAddress string
-	DBUser        string
-	DBPassword    string
-	DBName        string
-	DBHost        string
-	DBPort        string
+	// Storage — postgres, sqlite или memory; данные в памяти теряются при остановке
+	Storage      string             `yaml:"storage"`
+	HTTP         HTTPConfig         `yaml:"http"`
+	Database     DatabaseConfig     `yaml:"database"`
+	SQLite       SQLiteConfig       `yaml:"sqlite"`
+	Log          LogConfig          `yaml:"log"`
+	Assignment   AssignmentConfig   `yaml:"assignment"`
+	Workers      WorkersConfig      `yaml:"workers"`
+	Integrations IntegrationsConfig `yaml:"integrations"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Tracing      TracingConfig      `yaml:"tracing"`
+
+	// PrintConfig — вывести итоговую конфигурацию без секретов и выйти
+	PrintConfig bool `yaml:"-"`
+}
+
+type HTTPConfig struct {
+	Port            int           `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+func (c HTTPConfig) Address() string {
+	return ":" + strconv.Itoa(c.Port)
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	// SSLMode — режим TLS libpq: disable, allow, prefer, require, verify-ca, verify-full
+	SSLMode     string `yaml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert"`
+	// AutoMigrate — применять встроенные миграции при старте
+	AutoMigrate bool `yaml:"auto_migrate"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+type SQLiteConfig struct {
+	// Path — файл базы, создаётся при первом запуске
+	Path string `yaml:"path"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// AssignmentConfig — стратегия выбора ревьюеров и настройки назначения
+// для команд, у которых нет сохранённых настроек.
+type AssignmentConfig struct {
+	Strategy       string            `yaml:"strategy"`
+	TeamStrategies map[string]string `yaml:"team_strategies"`
+	ReviewerCount  int               `yaml:"reviewer_count"`
+	MinReviewers   int               `yaml:"min_reviewers"`
+	SLAHours       int               `yaml:"sla_hours"`
+	SLAAction      string            `yaml:"sla_action"`
+}
+
+type WorkersConfig struct {
+	SLACheckInterval        time.Duration `yaml:"sla_check_interval"`
+	WebhookDispatchInterval time.Duration `yaml:"webhook_dispatch_interval"`
+	WebhookTimeout          time.Duration `yaml:"webhook_timeout"`
+	WebhookMaxAttempts      int           `yaml:"webhook_max_attempts"`
+}
+
+type IntegrationsConfig struct {
+	GitHubWebhookSecret string `yaml:"github_webhook_secret"`
+	GitLabWebhookToken  string `yaml:"gitlab_webhook_token"`
+}
+
+type AuthConfig struct {
+	// Enabled — требовать токен API на всех маршрутах, кроме служебных
+	// и входящих вебхуков GitHub/GitLab
+	Enabled bool `yaml:"enabled"`
+	// AdminToken — токен администратора для выдачи первых токенов через API
+	AdminToken string    `yaml:"admin_token"`
+	JWT        JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig — проверка JWT от OIDC-провайдера; пустой JWKS отключает её.
+type JWTConfig struct {
+	// JWKS — путь к файлу или http(s)-URL набора ключей
+	JWKS        string        `yaml:"jwks"`
+	JWKSRefresh time.Duration `yaml:"jwks_refresh"`
+	Issuer      string        `yaml:"issuer"`
+	Audience    string        `yaml:"audience"`
+	// UserClaim — claim, значение которого — id пользователя сервиса
+	UserClaim string `yaml:"user_claim"`
+}
+
+type TracingConfig struct {
+	// Exporter — none, otlp, stdout или file
+	Exporter string `yaml:"exporter"`
+	File     string `yaml:"file"`
+}
+
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+func defaults() *Config {
+	return &Config{
+		Storage: StoragePostgres,
+		HTTP: HTTPConfig{
+			Port:            8080,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		SQLite: SQLiteConfig{
+			Path: "pr_reviewer.db",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "console",
+		},
+		Assignment: AssignmentConfig{
+			Strategy:       "random",
+			TeamStrategies: map[string]string{},
+			ReviewerCount:  2,
+			SLAAction:      "escalate",
+		},
+		Workers: WorkersConfig{
+			SLACheckInterval:        time.Minute,
+			WebhookDispatchInterval: 5 * time.Second,
+			WebhookTimeout:          10 * time.Second,
+			WebhookMaxAttempts:      8,
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				JWKSRefresh: 15 * time.Minute,
+				UserClaim:   "sub",
+			},
+		},
+		Tracing: TracingConfig{
+			Exporter: "none",
+			File:     "traces.jsonl",
+		},
+	}
 }
 
-func Load() (*Config, error) {
-	dbUser := os.Getenv("POSTGRES_USER")
-	dbPassword := os.Getenv("POSTGRES_PASSWORD")
-	dbName := os.Getenv("POSTGRES_DB")
-	dbHost := os.Getenv("POSTGRES_HOST")
-	dbPort := os.Getenv("POSTGRES_PORT")
-	httpPort := os.Getenv("APP_HTTP_PORT")
+// Load собирает конфигурацию по аргументам командной строки args (без имени
+// программы). Путь к YAML-файлу задаётся флагом --config или переменной CONFIG_FILE.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("pr_reviewer_service", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML config file")
+	printConfig := fs.Bool("print-config", false, "print effective config with secrets redacted and exit")
+
+	// Флаги применяются последними, поэтому здесь только запоминаем их значения
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		record := func(raw string) error {
+			flagValues[f.flag] = raw
+			return nil
+		}
+		if f.isBool {
+			fs.BoolFunc(f.flag, f.usage, record)
+		} else {
+			fs.Func(f.flag, f.usage, record)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
 
-	if dbUser == "" || dbPassword == "" || dbName == "" || dbHost == "" || dbPort == "" || httpPort == "" {
-		return nil, fmt.Errorf("one or more environment variables are missing")
+	cfg := defaults()
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, err
+		}
 	}
 
-	cfg := &Config{
-		ServerAddress: ":" + httpPort,
-		DBUser:        dbUser,
-		DBPassword:    dbPassword,
-		DBName:        dbName,
-		DBHost:        dbHost,
-		DBPort:        dbPort,
+	for _, f := range fields {
+		if raw, ok := os.LookupEnv(f.env); ok && raw != "" {
+			if err := f.set(cfg, raw); err != nil {
+				return nil, fmt.Errorf("invalid value %q for %s: %w", raw, f.env, err)
+			}
+		}
+	}
+	for _, f := range fields {
+		if raw, ok := flagValues[f.flag]; ok {
+			if err := f.set(cfg, raw); err != nil {
+				return nil, fmt.Errorf("invalid value %q for flag --%s: %w", raw, f.flag, err)
+			}
+		}
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.PrintConfig = *printConfig
+
 	return cfg, nil
 }
 
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
 func (c *Config) DSN() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+	query := url.Values{}
+	query.Set("sslmode", c.Database.SSLMode)
+	if c.Database.SSLRootCert != "" {
+		query.Set("sslrootcert", c.Database.SSLRootCert)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.Database.User, c.Database.Password),
+		Host:     net.JoinHostPort(c.Database.Host, strconv.Itoa(c.Database.Port)),
+		Path:     "/" + c.Database.Name,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+const redacted = "******"
+
+// Redacted возвращает копию конфигурации, в которой секреты заменены звёздочками.
+func (c *Config) Redacted() *Config {
+	out := *c
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	redact(&out.Database.Password)
+	redact(&out.Integrations.GitHubWebhookSecret)
+	redact(&out.Integrations.GitLabWebhookToken)
+	redact(&out.Auth.AdminToken)
+	return &out
+}
+
+// Print пишет конфигурацию без секретов в формате YAML.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
 }
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field описывает параметр, который можно задать переменной окружения
+// и флагом; key — путь к нему в YAML-файле, используется в ошибках.
+type field struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, raw string) error
+	// isBool разрешает флаг без значения: --auto-migrate
+	isBool bool
+}
+
+var fields = []field{
+	{"storage", "STORAGE", "storage", "storage backend: postgres, sqlite or memory", stringField(func(c *Config) *string { return &c.Storage }), false},
+
+	{"http.port", "APP_HTTP_PORT", "http-port", "HTTP port", intField(func(c *Config) *int { return &c.HTTP.Port }), false},
+	{"http.read_timeout", "HTTP_READ_TIMEOUT", "http-read-timeout", "request read timeout", durationField(func(c *Config) *time.Duration { return &c.HTTP.ReadTimeout }), false},
+	{"http.write_timeout", "HTTP_WRITE_TIMEOUT", "http-write-timeout", "response write timeout", durationField(func(c *Config) *time.Duration { return &c.HTTP.WriteTimeout }), false},
+	{"http.idle_timeout", "HTTP_IDLE_TIMEOUT", "http-idle-timeout", "keep-alive idle timeout", durationField(func(c *Config) *time.Duration { return &c.HTTP.IdleTimeout }), false},
+	{"http.shutdown_timeout", "SHUTDOWN_TIMEOUT", "shutdown-timeout", "graceful shutdown timeout", durationField(func(c *Config) *time.Duration { return &c.HTTP.ShutdownTimeout }), false},
+
+	{"database.host", "POSTGRES_HOST", "db-host", "PostgreSQL host", stringField(func(c *Config) *string { return &c.Database.Host }), false},
+	{"database.port", "POSTGRES_PORT", "db-port", "PostgreSQL port", intField(func(c *Config) *int { return &c.Database.Port }), false},
+	{"database.user", "POSTGRES_USER", "db-user", "PostgreSQL user", stringField(func(c *Config) *string { return &c.Database.User }), false},
+	{"database.password", "POSTGRES_PASSWORD", "db-password", "PostgreSQL password", stringField(func(c *Config) *string { return &c.Database.Password }), false},
+	{"database.name", "POSTGRES_DB", "db-name", "PostgreSQL database", stringField(func(c *Config) *string { return &c.Database.Name }), false},
+	{"database.sslmode", "POSTGRES_SSLMODE", "db-sslmode", "PostgreSQL TLS mode", stringField(func(c *Config) *string { return &c.Database.SSLMode }), false},
+	{"database.sslrootcert", "POSTGRES_SSLROOTCERT", "db-sslrootcert", "CA certificate for verify-ca/verify-full", stringField(func(c *Config) *string { return &c.Database.SSLRootCert }), false},
+	{"database.auto_migrate", "AUTO_MIGRATE", "auto-migrate", "apply embedded migrations on startup", boolField(func(c *Config) *bool { return &c.Database.AutoMigrate }), true},
+	{"database.max_open_conns", "DB_MAX_OPEN_CONNS", "db-max-open-conns", "max open connections (0 = unlimited)", intField(func(c *Config) *int { return &c.Database.MaxOpenConns }), false},
+	{"database.max_idle_conns", "DB_MAX_IDLE_CONNS", "db-max-idle-conns", "max idle connections", intField(func(c *Config) *int { return &c.Database.MaxIdleConns }), false},
+	{"database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "max connection lifetime (0 = unlimited)", durationField(func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime }), false},
+	{"database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "max connection idle time (0 = unlimited)", durationField(func(c *Config) *time.Duration { return &c.Database.ConnMaxIdleTime }), false},
+
+	{"sqlite.path", "SQLITE_PATH", "sqlite-path", "SQLite database file", stringField(func(c *Config) *string { return &c.SQLite.Path }), false},
+
+	{"log.level", "LOG_LEVEL", "log-level", "log level", stringField(func(c *Config) *string { return &c.Log.Level }), false},
+	{"log.format", "LOG_FORMAT", "log-format", "log format: json or console", stringField(func(c *Config) *string { return &c.Log.Format }), false},
+
+	{"assignment.strategy", "REVIEWER_STRATEGY", "reviewer-strategy", "default reviewer strategy", stringField(func(c *Config) *string { return &c.Assignment.Strategy }), false},
+	{"assignment.team_strategies", "REVIEWER_STRATEGY_TEAMS", "reviewer-strategy-teams", "per-team strategies, team:strategy,...", teamStrategiesField, false},
+	{"assignment.reviewer_count", "DEFAULT_REVIEWER_COUNT", "default-reviewer-count", "reviewers per PR for teams without settings", intField(func(c *Config) *int { return &c.Assignment.ReviewerCount }), false},
+	{"assignment.min_reviewers", "DEFAULT_MIN_REVIEWERS", "default-min-reviewers", "minimum reviewers for teams without settings", intField(func(c *Config) *int { return &c.Assignment.MinReviewers }), false},
+	{"assignment.sla_hours", "DEFAULT_SLA_HOURS", "default-sla-hours", "review SLA in hours for teams that do not set it (0 = none)", intField(func(c *Config) *int { return &c.Assignment.SLAHours }), false},
+	{"assignment.sla_action", "DEFAULT_SLA_ACTION", "default-sla-action", "SLA action for teams that do not set it", stringField(func(c *Config) *string { return &c.Assignment.SLAAction }), false},
+
+	{"workers.sla_check_interval", "SLA_CHECK_INTERVAL", "sla-check-interval", "overdue review check interval", durationField(func(c *Config) *time.Duration { return &c.Workers.SLACheckInterval }), false},
+	{"workers.webhook_dispatch_interval", "WEBHOOK_DISPATCH_INTERVAL", "webhook-dispatch-interval", "webhook dispatch interval", durationField(func(c *Config) *time.Duration { return &c.Workers.WebhookDispatchInterval }), false},
+	{"workers.webhook_timeout", "WEBHOOK_TIMEOUT", "webhook-timeout", "webhook request timeout", durationField(func(c *Config) *time.Duration { return &c.Workers.WebhookTimeout }), false},
+	{"workers.webhook_max_attempts", "WEBHOOK_MAX_ATTEMPTS", "webhook-max-attempts", "webhook delivery attempts", intField(func(c *Config) *int { return &c.Workers.WebhookMaxAttempts }), false},
+
+	{"integrations.github_webhook_secret", "GITHUB_WEBHOOK_SECRET", "github-webhook-secret", "GitHub webhook secret", stringField(func(c *Config) *string { return &c.Integrations.GitHubWebhookSecret }), false},
+	{"integrations.gitlab_webhook_token", "GITLAB_WEBHOOK_TOKEN", "gitlab-webhook-token", "GitLab webhook token", stringField(func(c *Config) *string { return &c.Integrations.GitLabWebhookToken }), false},
+
+	{"auth.enabled", "AUTH_ENABLED", "auth-enabled", "require API tokens", boolField(func(c *Config) *bool { return &c.Auth.Enabled }), true},
+	{"auth.admin_token", "AUTH_ADMIN_TOKEN", "auth-admin-token", "bootstrap admin API token", stringField(func(c *Config) *string { return &c.Auth.AdminToken }), false},
+	{"auth.jwt.jwks", "AUTH_JWKS", "auth-jwks", "JWKS file or http(s) URL for JWT validation", stringField(func(c *Config) *string { return &c.Auth.JWT.JWKS }), false},
+	{"auth.jwt.jwks_refresh", "AUTH_JWKS_REFRESH", "auth-jwks-refresh", "JWKS refresh interval", durationField(func(c *Config) *time.Duration { return &c.Auth.JWT.JWKSRefresh }), false},
+	{"auth.jwt.issuer", "AUTH_JWT_ISSUER", "auth-jwt-issuer", "expected JWT issuer (iss)", stringField(func(c *Config) *string { return &c.Auth.JWT.Issuer }), false},
+	{"auth.jwt.audience", "AUTH_JWT_AUDIENCE", "auth-jwt-audience", "expected JWT audience (aud)", stringField(func(c *Config) *string { return &c.Auth.JWT.Audience }), false},
+	{"auth.jwt.user_claim", "AUTH_JWT_USER_CLAIM", "auth-jwt-user-claim", "JWT claim holding the user id", stringField(func(c *Config) *string { return &c.Auth.JWT.UserClaim }), false},
+
+	{"tracing.exporter", "TRACE_EXPORTER", "trace-exporter", "trace exporter: none, otlp, stdout, file", stringField(func(c *Config) *string { return &c.Tracing.Exporter }), false},
+	{"tracing.file", "TRACE_FILE", "trace-file", "trace file for the file exporter", stringField(func(c *Config) *string { return &c.Tracing.File }), false},
+}
+
+// source возвращает подсказку, где задаётся параметр key.
+func source(key string) string {
+	for _, f := range fields {
+		if f.key == key {
+			return fmt.Sprintf("%s (%s, --%s)", key, f.env, f.flag)
+		}
+	}
+	return key
+}
+
+func stringField(p func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		*p(c) = raw
+		return nil
+	}
+}
+
+func intField(p func(c *Config) *int) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		*p(c) = v
+		return nil
+	}
+}
+
+func boolField(p func(c *Config) *bool) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		*p(c) = v
+		return nil
+	}
+}
+
+func durationField(p func(c *Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 30s or 5m")
+		}
+		*p(c) = v
+		return nil
+	}
+}
+
+// teamStrategiesField разбирает строку вида "backend:round_robin,frontend:least_loaded".
+func teamStrategiesField(c *Config, raw string) error {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		team, strategy, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || team == "" || strategy == "" {
+			return fmt.Errorf("entry %q is not team:strategy", pair)
+		}
+		result[team] = strategy
+	}
+	c.Assignment.TeamStrategies = result
+	return nil
+}
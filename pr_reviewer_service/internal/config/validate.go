@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+var (
+	storages       = []string{StoragePostgres, StorageSQLite, StorageMemory}
+	sslModes       = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels      = []string{"debug", "info", "warn", "error"}
+	logFormats     = []string{"json", "console"}
+	strategies     = []string{"random", "round_robin", "least_loaded"}
+	slaActions     = []string{"escalate", "reassign"}
+	traceExporters = []string{"none", "otlp", "stdout", "file"}
+)
+
+const minAdminTokenLength = 32
+
+// Validate проверяет всю конфигурацию и возвращает все найденные ошибки сразу.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", source(key), fmt.Sprintf(format, args...)))
+		}
+	}
+	positive := func(d time.Duration, key string) {
+		check(d > 0, key, "must be positive, got %s", d)
+	}
+	oneOf := func(v string, allowed []string, key string) {
+		check(slices.Contains(allowed, v), key, "must be one of %v, got %q", allowed, v)
+	}
+
+	oneOf(c.Storage, storages, "storage")
+
+	check(c.HTTP.Port > 0 && c.HTTP.Port <= 65535, "http.port", "must be between 1 and 65535, got %d", c.HTTP.Port)
+	positive(c.HTTP.ReadTimeout, "http.read_timeout")
+	positive(c.HTTP.WriteTimeout, "http.write_timeout")
+	positive(c.HTTP.IdleTimeout, "http.idle_timeout")
+	positive(c.HTTP.ShutdownTimeout, "http.shutdown_timeout")
+
+	// Параметры подключения нужны только хранилищу в PostgreSQL
+	if c.Storage == StoragePostgres {
+		check(c.Database.Host != "", "database.host", "is required")
+		check(c.Database.User != "", "database.user", "is required")
+		check(c.Database.Password != "", "database.password", "is required")
+		check(c.Database.Name != "", "database.name", "is required")
+	}
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port", "must be between 1 and 65535, got %d", c.Database.Port)
+	oneOf(c.Database.SSLMode, sslModes, "database.sslmode")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative, got %d", c.Database.MaxOpenConns)
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns", "must not be negative, got %d", c.Database.MaxIdleConns)
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns", "must not exceed database.max_open_conns (%d), got %d", c.Database.MaxOpenConns, c.Database.MaxIdleConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+
+	check(c.Storage != StorageSQLite || c.SQLite.Path != "", "sqlite.path", "is required for sqlite storage")
+
+	oneOf(c.Log.Level, logLevels, "log.level")
+	oneOf(c.Log.Format, logFormats, "log.format")
+
+	oneOf(c.Assignment.Strategy, strategies, "assignment.strategy")
+	teams := slices.Sorted(maps.Keys(c.Assignment.TeamStrategies))
+	for _, team := range teams {
+		strategy := c.Assignment.TeamStrategies[team]
+		check(slices.Contains(strategies, strategy), "assignment.team_strategies",
+			"team %q: strategy must be one of %v, got %q", team, strategies, strategy)
+	}
+	check(c.Assignment.ReviewerCount >= 0, "assignment.reviewer_count", "must not be negative, got %d", c.Assignment.ReviewerCount)
+	check(c.Assignment.MinReviewers >= 0 && c.Assignment.MinReviewers <= c.Assignment.ReviewerCount,
+		"assignment.min_reviewers", "must be between 0 and assignment.reviewer_count (%d), got %d", c.Assignment.ReviewerCount, c.Assignment.MinReviewers)
+	check(c.Assignment.SLAHours >= 0, "assignment.sla_hours", "must not be negative, got %d", c.Assignment.SLAHours)
+	oneOf(c.Assignment.SLAAction, slaActions, "assignment.sla_action")
+
+	positive(c.Workers.SLACheckInterval, "workers.sla_check_interval")
+	positive(c.Workers.WebhookDispatchInterval, "workers.webhook_dispatch_interval")
+	positive(c.Workers.WebhookTimeout, "workers.webhook_timeout")
+	check(c.Workers.WebhookMaxAttempts >= 1, "workers.webhook_max_attempts", "must be at least 1, got %d", c.Workers.WebhookMaxAttempts)
+
+	// Токены в памяти теряются при перезапуске, выдать их можно только токеном из конфигурации
+	check(!c.Auth.Enabled || c.Storage != StorageMemory || c.Auth.AdminToken != "",
+		"auth.admin_token", "is required when auth is enabled with memory storage")
+	check(c.Auth.AdminToken == "" || len(c.Auth.AdminToken) >= minAdminTokenLength,
+		"auth.admin_token", "must be at least %d characters", minAdminTokenLength)
+
+	if c.Auth.JWT.JWKS != "" {
+		check(c.Auth.Enabled, "auth.jwt.jwks", "requires auth.enabled")
+		positive(c.Auth.JWT.JWKSRefresh, "auth.jwt.jwks_refresh")
+		check(c.Auth.JWT.UserClaim != "", "auth.jwt.user_claim", "is required")
+	}
+
+	oneOf(c.Tracing.Exporter, traceExporters, "tracing.exporter")
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file", "is required for the file exporter")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
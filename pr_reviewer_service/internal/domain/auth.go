@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+type Role string
+
+const (
+	// RoleAdmin — полный доступ, в том числе выдача и отзыв токенов
+	RoleAdmin Role = "admin"
+	// RoleTeamMaintainer — изменения в пределах своей команды
+	RoleTeamMaintainer Role = "team_maintainer"
+	// RoleUser — чтение и отказ от своих ревью
+	RoleUser Role = "user"
+	// RoleService — CI и интеграции: создание PR, смена статуса и ревью
+	RoleService Role = "service"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleTeamMaintainer, RoleUser, RoleService:
+		return true
+	}
+	return false
+}
+
+// APIToken — выданный токен API. Сам токен не хранится, только его хэш.
+// TeamName задан у team_maintainer, UserID — у user.
+type APIToken struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      Role       `json:"role"`
+	TeamName  string     `json:"team_name,omitempty"`
+	UserID    string     `json:"user_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// Active сообщает, можно ли аутентифицироваться токеном в момент now.
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
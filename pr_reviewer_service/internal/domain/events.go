@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventPRCreated         EventType = "pull_request.created"
+	EventPRReassigned      EventType = "pull_request.reassigned"
+	EventReviewerAdded     EventType = "pull_request.reviewer_added"
+	EventReviewerRemoved   EventType = "pull_request.reviewer_removed"
+	EventPRReady           EventType = "pull_request.ready"
+	EventPRReopened        EventType = "pull_request.reopened"
+	EventPRClosed          EventType = "pull_request.closed"
+	EventPRMerged          EventType = "pull_request.merged"
+	EventReviewSLAEscalate EventType = "review.sla_escalated"
+)
+
+func (t EventType) Valid() bool {
+	switch t {
+	case EventPRCreated, EventPRReassigned, EventReviewerAdded, EventReviewerRemoved,
+		EventPRReady, EventPRReopened, EventPRClosed, EventPRMerged, EventReviewSLAEscalate:
+		return true
+	}
+	return false
+}
+
+// OutboxEvent — событие, записанное в той же транзакции, что и изменение,
+// и позже разосланное подписчикам вебхуков.
+type OutboxEvent struct {
+	ID        int64           `json:"id"`
+	Type      EventType       `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PullRequestEvent — данные событий pull_request.created, pull_request.ready,
+// pull_request.reopened, pull_request.closed и pull_request.merged.
+type PullRequestEvent struct {
+	PullRequestID string     `json:"pull_request_id"`
+	Name          string     `json:"pull_request_name"`
+	AuthorID      string     `json:"author_id"`
+	Status        PRStatus   `json:"status"`
+	Reviewers     []string   `json:"assigned_reviewers"`
+	MergedAt      *time.Time `json:"merged_at,omitempty"`
+}
+
+// ReassignedEvent — данные события pull_request.reassigned.
+type ReassignedEvent struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+// ReviewerEvent — данные событий pull_request.reviewer_added и pull_request.reviewer_removed.
+type ReviewerEvent struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+}
+
+type WebhookSubscription struct {
+	ID        int64       `json:"id"`
+	URL       string      `json:"url"`
+	Secret    string      `json:"-"`
+	Events    []EventType `json:"events"`
+	IsActive  bool        `json:"is_active"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery — попытки доставки одного события одному подписчику.
+type WebhookDelivery struct {
+	ID             int64          `json:"id"`
+	SubscriptionID int64          `json:"subscription_id"`
+	EventID        int64          `json:"event_id"`
+	EventType      EventType      `json:"event"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	ResponseStatus *int           `json:"response_status"`
+	LastError      *string        `json:"last_error"`
+	CreatedAt      time.Time      `json:"created_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+
+	// Заполняются только для взятых в работу доставок
+	URL     string          `json:"-"`
+	Secret  string          `json:"-"`
+	Payload json.RawMessage `json:"-"`
+}
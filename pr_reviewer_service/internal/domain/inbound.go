@@ -0,0 +1,51 @@
+package domain
+
+// Provider — внешняя система, присылающая события о pull request.
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+)
+
+func (p Provider) Valid() bool {
+	switch p {
+	case ProviderGitHub, ProviderGitLab:
+		return true
+	}
+	return false
+}
+
+// ExternalAccount связывает логин во внешней системе с пользователем сервиса.
+type ExternalAccount struct {
+	Provider Provider `json:"provider"`
+	Login    string   `json:"login"`
+	UserID   string   `json:"user_id"`
+}
+
+type ExternalPRAction string
+
+const (
+	ExternalPROpened   ExternalPRAction = "opened"
+	ExternalPRClosed   ExternalPRAction = "closed"
+	ExternalPRMerged   ExternalPRAction = "merged"
+	ExternalPRReopened ExternalPRAction = "reopened"
+	ExternalPRReady    ExternalPRAction = "ready"
+)
+
+// ExternalPREvent — событие pull request внешней системы, приведённое
+// к действиям сервиса. PullRequestID уже включает имя репозитория.
+type ExternalPREvent struct {
+	Provider      Provider
+	Action        ExternalPRAction
+	PullRequestID string
+	Title         string
+	AuthorLogin   string
+	// AuthorID — id автора во внешней системе; привязка с таким логином
+	// проверяется раньше AuthorLogin
+	AuthorID string
+	// SenderLogin — кто выполнил действие; для слияния попадает в журнал
+	SenderLogin string
+	Labels      []string
+	Draft       bool
+}
@@ -7,20 +7,171 @@ type Team struct {
 	Members []User `json:"members"`
 }
 
+// TeamSettings — параметры назначения ревьюеров для команды.
+// Пустая Strategy означает глобальную стратегию из конфига.
+// FallbackTeams — резервные команды в порядке приоритета, из которых
+// добираются ревьюеры, если в самой команде активных не хватает.
+// ReassignOnDeactivate включает перераспределение открытых ревью
+// при деактивации участника команды. RequiredApprovals и
+// BlockOnChangesRequested задают политику слияния PR авторов команды.
+// SLAHours — срок первой реакции ревьюера (0 — без SLA), SLAAction —
+// что делать с просроченным ревью; nil и пустая строка означают значения
+// из конфигурации. OwnerFallback разрешает создавать PR
+// без владельца кода, если ни один из владельцев недоступен.
+type TeamSettings struct {
+	TeamName             string   `json:"team_name"`
+	ReviewerCount        int      `json:"reviewer_count"`
+	MinReviewers         int      `json:"min_reviewers"`
+	Strategy             string   `json:"strategy"`
+	FallbackTeams        []string `json:"fallback_teams"`
+	ReassignOnDeactivate bool     `json:"reassign_on_deactivate"`
+	OwnerFallback        bool     `json:"owner_fallback"`
+
+	RequiredApprovals       int  `json:"required_approvals"`
+	BlockOnChangesRequested bool `json:"block_on_changes_requested"`
+
+	SLAHours  *int      `json:"sla_hours"`
+	SLAAction SLAAction `json:"sla_action"`
+}
+
+// TeamSettingsUpdate — частичное изменение настроек команды: nil-поля
+// оставляют сохранённые значения без изменений.
+type TeamSettingsUpdate struct {
+	TeamName      string
+	ReviewerCount *int
+	MinReviewers  *int
+	// Пустая строка возвращает команде глобальную стратегию
+	Strategy *string
+	// Пустой слайс очищает список резервных команд
+	FallbackTeams        []string
+	ReassignOnDeactivate *bool
+	OwnerFallback        *bool
+
+	RequiredApprovals       *int
+	BlockOnChangesRequested *bool
+
+	SLAHours *int
+	// Пустая строка возвращает команде действие из конфигурации
+	SLAAction *SLAAction
+}
+
+type SLAAction string
+
+const (
+	SLAEscalate SLAAction = "escalate"
+	SLAReassign SLAAction = "reassign"
+)
+
+func (a SLAAction) Valid() bool {
+	switch a {
+	case SLAEscalate, SLAReassign:
+		return true
+	}
+	return false
+}
+
+// SLAPolicy — SLA для команд без сохранённых настроек: срок первой реакции
+// в часах (0 — без SLA) и действие при его нарушении.
+type SLAPolicy struct {
+	Hours  int
+	Action SLAAction
+}
+
+// ReviewAssignment — назначение ревьюера на PR с отметками времени для SLA.
+// TeamName и SLAHours относятся к команде автора PR.
+type ReviewAssignment struct {
+	PullRequestID string     `json:"pull_request_id"`
+	ReviewerID    string     `json:"reviewer_id"`
+	AssignedAt    time.Time  `json:"assigned_at"`
+	FirstActionAt *time.Time `json:"first_action_at"`
+	TeamName      string     `json:"team_name"`
+	SLAHours      int        `json:"sla_hours"`
+	SLAAction     SLAAction  `json:"sla_action"`
+}
+
+// SLAReport — итог одной проверки просроченных ревью.
+type SLAReport struct {
+	Escalated  []ReviewAssignment    `json:"escalated"`
+	Reassigned []Reassignment        `json:"reassigned"`
+	Failed     []ReassignmentFailure `json:"failed"`
+}
+
 type User struct {
 	ID       string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
 	TeamName string `json:"-"`
+	// Tags — области экспертизы пользователя (go, sql, frontend, ...)
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Absence — период отсутствия пользователя. Пока он длится,
+// пользователь не назначается ревьюером, флаг is_active не меняется.
+type Absence struct {
+	ID       int64     `json:"id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+// Candidate — активный пользователь, которого можно назначить ревьюером,
+// вместе с числом открытых PR, где он уже ревьюер.
+type Candidate struct {
+	User
+	OpenReviews int
 }
 
 type PRStatus string
 
 const (
+	PRStatusDraft  PRStatus = "DRAFT"
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	PRStatusClosed PRStatus = "CLOSED"
+)
+
+func (s PRStatus) Valid() bool {
+	switch s {
+	case PRStatusDraft, PRStatusOpen, PRStatusMerged, PRStatusClosed:
+		return true
+	}
+	return false
+}
+
+type ReviewDecision string
+
+const (
+	ReviewApproved         ReviewDecision = "APPROVED"
+	ReviewChangesRequested ReviewDecision = "CHANGES_REQUESTED"
+	ReviewCommented        ReviewDecision = "COMMENTED"
 )
 
+func (d ReviewDecision) Valid() bool {
+	switch d {
+	case ReviewApproved, ReviewChangesRequested, ReviewCommented:
+		return true
+	}
+	return false
+}
+
+// Review — решение ревьюера по PR. Хранится вся история решений.
+type Review struct {
+	PullRequestID string         `json:"pull_request_id"`
+	ReviewerID    string         `json:"reviewer_id"`
+	Decision      ReviewDecision `json:"decision"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// MergeOverride — запись о принудительном слиянии в обход политики.
+type MergeOverride struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ForcedBy      string    `json:"forced_by"`
+	Reason        string    `json:"reason"`
+	UnmetPolicy   string    `json:"unmet_policy"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 type PullRequest struct {
 	ID        string
 	Name      string
@@ -28,7 +179,17 @@ type PullRequest struct {
 	Status    PRStatus
 	CreatedAt time.Time
 	MergedAt  *time.Time
+	ClosedAt  *time.Time
 	Reviewers []User
+	// Labels — метки PR, сопоставляются с тегами ревьюеров
+	Labels []string
+	// ChangedFiles — пути изменённых файлов, по ним ищутся владельцы кода
+	ChangedFiles []string
+	// FallbackReviewerIDs — ревьюеры, назначенные из резервных команд
+	FallbackReviewerIDs []string
+	// OwnerMissing — у изменённых файлов есть владельцы, но ни одного
+	// не удалось назначить, и ревьюеры выбраны без их учёта
+	OwnerMissing bool
 }
 
 type PullRequestShort struct {
@@ -37,3 +198,37 @@ type PullRequestShort struct {
 	AuthorID string   `json:"author_id"`
 	Status   PRStatus `json:"status"`
 }
+
+type OwnerType string
+
+const (
+	OwnerUser OwnerType = "user"
+	OwnerTeam OwnerType = "team"
+)
+
+type Owner struct {
+	Type OwnerType `json:"type"`
+	ID   string    `json:"id"`
+}
+
+// OwnershipRule — строка CODEOWNERS: шаблон пути и его владельцы.
+type OwnershipRule struct {
+	Pattern string  `json:"pattern"`
+	Owners  []Owner `json:"owners"`
+}
+
+// ReassignmentReport — результат перераспределения открытых ревью пользователя.
+type ReassignmentReport struct {
+	Reassigned []Reassignment        `json:"reassigned"`
+	Failed     []ReassignmentFailure `json:"failed"`
+}
+
+type Reassignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+type ReassignmentFailure struct {
+	PullRequestID string `json:"pull_request_id"`
+	Reason        string `json:"reason"`
+}
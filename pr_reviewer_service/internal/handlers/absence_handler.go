@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type scheduleAbsenceRequest struct {
+	UserID   string    `json:"user_id" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+func (h *Handler) scheduleAbsence(c *gin.Context) {
+	var req scheduleAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizeUser(c, req.UserID) {
+		return
+	}
+
+	absence, err := h.svc.ScheduleAbsence(c.Request.Context(), domain.Absence{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrInvalidAbsence:
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		case service.ErrUserNotFound:
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"absence": absence})
+}
+
+func (h *Handler) listAbsences(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "user_id query param is required")
+		return
+	}
+
+	absences, err := h.svc.ListAbsences(c.Request.Context(), userID)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  userID,
+		"absences": absences,
+	})
+}
+
+type cancelAbsenceRequest struct {
+	UserID    string `json:"user_id" binding:"required"`
+	AbsenceID int64  `json:"absence_id" binding:"required"`
+}
+
+func (h *Handler) cancelAbsence(c *gin.Context) {
+	var req cancelAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizeUser(c, req.UserID) {
+		return
+	}
+
+	if err := h.svc.CancelAbsence(c.Request.Context(), req.UserID, req.AbsenceID); err != nil {
+		if err == service.ErrAbsenceNotFound {
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "absence not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/jwtauth"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/service"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const tokenKey = "api_token"
+
+// authenticate требует заголовок Authorization: Bearer <token> — токен API
+// или, если настроена проверка JWT, JWT от OIDC-провайдера — и кладёт права
+// вызывающего в контекст запроса.
+func (h *Handler) authenticate(c *gin.Context) {
+	raw, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		newErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "bearer token required")
+		return
+	}
+
+	// Токен из конфигурации нужен, чтобы выдать первые токены через API
+	if h.opts.AdminToken != "" && subtle.ConstantTimeCompare([]byte(raw), []byte(h.opts.AdminToken)) == 1 {
+		h.setCaller(c, &domain.APIToken{Name: "bootstrap", Role: domain.RoleAdmin})
+		return
+	}
+
+	var (
+		token *domain.APIToken
+		err   error
+	)
+	if h.opts.JWT != nil && jwtauth.LooksLikeJWT(raw) {
+		token, err = h.authenticateJWT(c.Request.Context(), raw)
+	} else {
+		token, err = h.svc.Authenticate(c.Request.Context(), raw)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthenticated) || errors.Is(err, jwtauth.ErrInvalidToken) {
+			logging.FromContext(c.Request.Context()).Debug("Authentication failed", zap.Error(err))
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			newErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", service.ErrUnauthenticated.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	h.setCaller(c, token)
+}
+
+func (h *Handler) authenticateJWT(ctx context.Context, raw string) (*domain.APIToken, error) {
+	userID, err := h.opts.JWT.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	return h.svc.AuthenticateUser(ctx, userID)
+}
+
+// setCaller запоминает права вызывающего; если он — пользователь сервиса,
+// методы сервиса и логи запроса получают его как действующего пользователя.
+func (h *Handler) setCaller(c *gin.Context, token *domain.APIToken) {
+	c.Set(tokenKey, token)
+	if token.UserID == "" {
+		return
+	}
+	ctx := service.WithActingUser(c.Request.Context(), token.UserID)
+	ctx = logging.WithContext(ctx, logging.FromContext(ctx).With(zap.String("user_id", token.UserID)))
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// apiToken возвращает токен запроса; nil — аутентификация отключена.
+func apiToken(c *gin.Context) *domain.APIToken {
+	token, _ := c.Get(tokenKey)
+	t, _ := token.(*domain.APIToken)
+	return t
+}
+
+// callerName возвращает, кто выполняет запрос, для журналов аудита:
+// пользователя, от имени которого выполняется вызов, иначе имя токена.
+// Пустая строка — аутентификация отключена.
+func callerName(c *gin.Context) string {
+	if userID, ok := service.ActingUser(c.Request.Context()); ok {
+		return userID
+	}
+	if token := apiToken(c); token != nil {
+		return token.Name
+	}
+	return ""
+}
+
+// allow пропускает к маршруту только токены с перечисленными ролями.
+func allow(roles ...domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := apiToken(c)
+		if token != nil && !slices.Contains(roles, token.Role) {
+			newErrorResponse(c, http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("role %s is not allowed to call %s", token.Role, c.FullPath()))
+		}
+	}
+}
+
+// authorize проверяет доступ токена к данным запроса и при отказе отвечает
+// ошибкой; возвращает false, если обработку нужно прервать.
+func (h *Handler) authorize(c *gin.Context, check func(ctx context.Context, token *domain.APIToken) error) bool {
+	token := apiToken(c)
+	if token == nil {
+		return true
+	}
+
+	err := check(c.Request.Context(), token)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, "FORBIDDEN", "token is not allowed to modify this resource")
+	case errors.Is(err, service.ErrUserNotFound):
+		newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+	case errors.Is(err, service.ErrPRNotFound):
+		newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "pull request not found")
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return false
+}
+
+func (h *Handler) authorizeTeam(c *gin.Context, teamName string) bool {
+	return h.authorize(c, func(ctx context.Context, token *domain.APIToken) error {
+		return h.svc.AuthorizeTeam(ctx, token, teamName)
+	})
+}
+
+func (h *Handler) authorizeUser(c *gin.Context, userID string) bool {
+	return h.authorize(c, func(ctx context.Context, token *domain.APIToken) error {
+		return h.svc.AuthorizeUser(ctx, token, userID)
+	})
+}
+
+func (h *Handler) authorizePR(c *gin.Context, prID string) bool {
+	return h.authorize(c, func(ctx context.Context, token *domain.APIToken) error {
+		return h.svc.AuthorizePR(ctx, token, prID)
+	})
+}
+
+func (h *Handler) authorizeReviewer(c *gin.Context, prID, reviewerID string) bool {
+	return h.authorize(c, func(ctx context.Context, token *domain.APIToken) error {
+		return h.svc.AuthorizeReviewer(ctx, token, prID, reviewerID)
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pr-reviewer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) getCodeOwners(c *gin.Context) {
+	rules, err := h.svc.GetCodeOwners(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// importCodeOwners принимает содержимое файла CODEOWNERS в теле запроса как есть.
+func (h *Handler) importCodeOwners(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+
+	rules, err := h.svc.ImportCodeOwners(c.Request.Context(), string(body))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCodeOwners) {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
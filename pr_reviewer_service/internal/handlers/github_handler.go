@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/service"
+	"pr-reviewer/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboundPayload — предел размера тела входящего вебхука (у GitHub это 25 МБ).
+const maxInboundPayload = 25 << 20
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// toExternalEvent возвращает false для действий, которые сервис не обрабатывает.
+func (e githubPullRequestEvent) toExternalEvent() (domain.ExternalPREvent, bool) {
+	ev := domain.ExternalPREvent{
+		Provider:      domain.ProviderGitHub,
+		PullRequestID: fmt.Sprintf("%s#%d", e.Repository.FullName, e.Number),
+		Title:         e.PullRequest.Title,
+		AuthorLogin:   e.PullRequest.User.Login,
+		SenderLogin:   e.Sender.Login,
+		Draft:         e.PullRequest.Draft,
+	}
+	for _, l := range e.PullRequest.Labels {
+		ev.Labels = append(ev.Labels, l.Name)
+	}
+
+	switch e.Action {
+	case "opened":
+		ev.Action = domain.ExternalPROpened
+	case "closed":
+		ev.Action = domain.ExternalPRClosed
+		if e.PullRequest.Merged {
+			ev.Action = domain.ExternalPRMerged
+		}
+	case "reopened":
+		ev.Action = domain.ExternalPRReopened
+	case "ready_for_review":
+		ev.Action = domain.ExternalPRReady
+	default:
+		return ev, false
+	}
+	return ev, true
+}
+
+func (h *Handler) githubWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxInboundPayload))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "failed to read body")
+		return
+	}
+
+	if !webhook.Verify(h.opts.GitHubWebhookSecret, body, c.GetHeader("X-Hub-Signature-256")) {
+		newErrorResponse(c, http.StatusUnauthorized, "INVALID_SIGNATURE", "signature does not match")
+		return
+	}
+
+	switch c.GetHeader("X-GitHub-Event") {
+	case "ping":
+		c.JSON(http.StatusOK, gin.H{"status": "pong"})
+		return
+	case "pull_request":
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Repository.FullName == "" || payload.Number == 0 {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid pull_request event")
+		return
+	}
+
+	ev, ok := payload.toExternalEvent()
+	if !ok {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored", "action": payload.Action})
+		return
+	}
+
+	h.applyExternalEvent(c, ev)
+}
+
+// applyExternalEvent выполняет событие внешней системы и отвечает итоговым PR.
+func (h *Handler) applyExternalEvent(c *gin.Context, ev domain.ExternalPREvent) {
+	pr, err := h.svc.ApplyExternalPREvent(c.Request.Context(), ev)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPRNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "pull request not found")
+		case errors.Is(err, service.ErrAuthorNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "no user is linked to "+externalAuthor(ev))
+		case errors.Is(err, service.ErrInvalidTransition):
+			newErrorResponse(c, http.StatusConflict, "INVALID_TRANSITION", err.Error())
+		case errors.Is(err, service.ErrNotEnoughReviewers):
+			newErrorResponse(c, http.StatusConflict, "NOT_ENOUGH_REVIEWERS", "not enough active reviewers in team")
+		case errors.Is(err, service.ErrNoOwnerAvailable):
+			newErrorResponse(c, http.StatusConflict, "NO_OWNER_AVAILABLE", "no active code owner available for changed files")
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"action": ev.Action, "pr": toPRResponse(pr)})
+}
+
+// externalAuthor описывает автора события для сообщения об ошибке.
+func externalAuthor(ev domain.ExternalPREvent) string {
+	if ev.AuthorLogin == "" {
+		return fmt.Sprintf("%s user id %s", ev.Provider, ev.AuthorID)
+	}
+	return "login " + ev.AuthorLogin
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type gitlabBoolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	// User — кто выполнил действие, не обязательно автор MR
+	User struct {
+		ID       int    `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int    `json:"iid"`
+		Title    string `json:"title"`
+		AuthorID int    `json:"author_id"`
+		Action   string `json:"action"`
+		Draft    bool   `json:"draft"`
+		// WorkInProgress — прежнее название draft в старых версиях GitLab
+		WorkInProgress bool `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+	Changes struct {
+		Draft          *gitlabBoolChange `json:"draft"`
+		WorkInProgress *gitlabBoolChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+// toExternalEvent возвращает false для действий, которые сервис не обрабатывает.
+// Автор MR известен только по id пользователя GitLab; его логин берётся
+// из события, лишь когда действие выполнил сам автор.
+func (e gitlabMergeRequestEvent) toExternalEvent() (domain.ExternalPREvent, bool) {
+	attrs := e.ObjectAttributes
+	ev := domain.ExternalPREvent{
+		Provider:      domain.ProviderGitLab,
+		PullRequestID: fmt.Sprintf("%s!%d", e.Project.PathWithNamespace, attrs.IID),
+		Title:         attrs.Title,
+		SenderLogin:   e.User.Username,
+		Draft:         attrs.Draft || attrs.WorkInProgress,
+	}
+	if attrs.AuthorID != 0 {
+		ev.AuthorID = strconv.Itoa(attrs.AuthorID)
+		if e.User.ID == attrs.AuthorID {
+			ev.AuthorLogin = e.User.Username
+		}
+	}
+	for _, l := range e.Labels {
+		ev.Labels = append(ev.Labels, l.Title)
+	}
+
+	switch attrs.Action {
+	case "open":
+		ev.Action = domain.ExternalPROpened
+	case "merge":
+		ev.Action = domain.ExternalPRMerged
+	case "close":
+		ev.Action = domain.ExternalPRClosed
+	case "reopen":
+		ev.Action = domain.ExternalPRReopened
+	case "update":
+		// Из обновлений интересно только снятие статуса черновика
+		change := e.Changes.Draft
+		if change == nil {
+			change = e.Changes.WorkInProgress
+		}
+		if change == nil || !change.Previous || change.Current {
+			return ev, false
+		}
+		ev.Action = domain.ExternalPRReady
+	default:
+		return ev, false
+	}
+	return ev, true
+}
+
+func (h *Handler) gitlabWebhook(c *gin.Context) {
+	token := c.GetHeader("X-Gitlab-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.GitLabWebhookToken)) != 1 {
+		newErrorResponse(c, http.StatusUnauthorized, "INVALID_TOKEN", "token does not match")
+		return
+	}
+
+	if c.GetHeader("X-Gitlab-Event") != "Merge Request Hook" {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxInboundPayload))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "failed to read body")
+		return
+	}
+
+	var payload gitlabMergeRequestEvent
+	err = json.Unmarshal(body, &payload)
+	if err != nil || payload.ObjectKind != "merge_request" || payload.Project.PathWithNamespace == "" || payload.ObjectAttributes.IID == 0 {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid merge request event")
+		return
+	}
+
+	ev, ok := payload.toExternalEvent()
+	if !ok {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored", "action": payload.ObjectAttributes.Action})
+		return
+	}
+
+	h.applyExternalEvent(c, ev)
+}
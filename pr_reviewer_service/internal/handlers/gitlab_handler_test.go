@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository/memory"
+	"pr-reviewer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testGitLabToken = "gitlab-token"
+
+func newGitLabRouter(t *testing.T) (*gin.Engine, *service.Service) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	selectors, err := service.NewTeamSelectors(string(service.StrategyRoundRobin), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewService(
+		memory.NewStore(), memory.NewTeamRepo(), memory.NewUserRepo(), memory.NewPRRepo(),
+		memory.NewCodeOwnersRepo(), memory.NewWebhookRepo(), memory.NewTokenRepo(),
+		selectors, domain.TeamSettings{ReviewerCount: 1, SLAAction: domain.SLAEscalate},
+	)
+
+	members := []domain.User{}
+	for _, id := range []string{"u1", "u2", "u3"} {
+		members = append(members, domain.User{ID: id, Username: id, IsActive: true})
+	}
+	if err := svc.CreateTeam(context.Background(), domain.Team{Name: "backend", Members: members}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	NewHandler(svc, Options{GitLabWebhookToken: testGitLabToken}).InitRoutes(router)
+	return router, svc
+}
+
+func postGitLabEvent(router *gin.Engine, payload string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/gitlab", strings.NewReader(payload))
+	req.Header.Set("X-Gitlab-Token", testGitLabToken)
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// gitlabOpenEvent — событие открытия MR, которое отправил sender,
+// а автором указан пользователь GitLab с id authorID.
+func gitlabOpenEvent(senderID int, sender string, iid, authorID int) string {
+	payload, _ := json.Marshal(map[string]any{
+		"object_kind": "merge_request",
+		"user":        map[string]any{"id": senderID, "username": sender},
+		"project":     map[string]any{"path_with_namespace": "group/project"},
+		"object_attributes": map[string]any{
+			"iid":       iid,
+			"title":     "Add feature",
+			"author_id": authorID,
+			"action":    "open",
+		},
+	})
+	return string(payload)
+}
+
+func TestGitLabWebhookAuthorFromAuthorID(t *testing.T) {
+	router, svc := newGitLabRouter(t)
+	_, err := svc.SetExternalLogin(context.Background(), domain.ExternalAccount{
+		Provider: domain.ProviderGitLab, Login: "42", UserID: "u1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// MR открыл u2 (например, бот или мейнтейнер) от имени автора с id 42
+	w := postGitLabEvent(router, gitlabOpenEvent(7, "u2", 1, 42))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	var resp struct {
+		PR struct {
+			ID       string `json:"pull_request_id"`
+			AuthorID string `json:"author_id"`
+		} `json:"pr"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.PR.ID != "group/project!1" || resp.PR.AuthorID != "u1" {
+		t.Errorf("pr = %+v, want group/project!1 authored by u1", resp.PR)
+	}
+}
+
+func TestGitLabWebhookUnlinkedAuthor(t *testing.T) {
+	router, _ := newGitLabRouter(t)
+
+	// Логин отправителя не выдаётся за автора, даже если такой пользователь есть
+	w := postGitLabEvent(router, gitlabOpenEvent(7, "u2", 1, 42))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "gitlab user id 42") {
+		t.Errorf("status = %d, body = %s, want 404 for gitlab user id 42", w.Code, w.Body)
+	}
+
+	// Событие от самого автора без привязки id ищет пользователя по логину
+	w = postGitLabEvent(router, gitlabOpenEvent(42, "u3", 2, 42))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"author_id":"u3"`) {
+		t.Errorf("status = %d, body = %s, want PR authored by u3", w.Code, w.Body)
+	}
+}
@@ -1,29 +1,96 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/jwtauth"
 	"pr-reviewer/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Options — настройки обработчиков, не относящиеся к сервису.
+type Options struct {
+	// GitHubWebhookSecret — секрет вебхука GitHub; пустой отключает /webhooks/github
+	GitHubWebhookSecret string
+	// GitLabWebhookToken — токен вебхука GitLab; пустой отключает /webhooks/gitlab
+	GitLabWebhookToken string
+	// AuthEnabled — требовать токен API; без него все маршруты анонимные
+	AuthEnabled bool
+	// AdminToken — токен администратора из конфигурации, не хранится в БД
+	AdminToken string
+	// JWT проверяет JWT от OIDC-провайдера; nil — принимаются только токены API
+	JWT *jwtauth.Verifier
+}
+
 type Handler struct {
-	svc *service.Service
+	svc  *service.Service
+	opts Options
 }
 
-func NewHandler(svc *service.Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *service.Service, opts Options) *Handler {
+	return &Handler{svc: svc, opts: opts}
 }
 
 func (h *Handler) InitRoutes(router *gin.Engine) {
-	router.POST("/team/add", h.createTeam)
-	router.GET("/team/get", h.getTeam)
+	// Вебхуки GitHub и GitLab проверяются своей подписью, а не токеном API
+	if h.opts.GitHubWebhookSecret != "" {
+		router.POST("/webhooks/github", h.githubWebhook)
+	}
+	if h.opts.GitLabWebhookToken != "" {
+		router.POST("/webhooks/gitlab", h.gitlabWebhook)
+	}
+
+	// Читать может любой действующий токен, изменения ограничены ролями.
+	// Мейнтейнер и пользователь дополнительно ограничены данными запроса
+	// (своя команда, свои ревью) — это проверяют сами обработчики.
+	api := router.Group("")
+	if h.opts.AuthEnabled {
+		api.Use(h.authenticate)
+	}
+	admin := allow(domain.RoleAdmin)
+	maintainer := allow(domain.RoleAdmin, domain.RoleTeamMaintainer)
+	ci := allow(domain.RoleAdmin, domain.RoleTeamMaintainer, domain.RoleService)
+	reviewer := allow(domain.RoleAdmin, domain.RoleTeamMaintainer, domain.RoleUser)
+
+	api.POST("/team/add", admin, h.createTeam)
+	api.GET("/team/get", h.getTeam)
+	api.GET("/team/settings", h.getTeamSettings)
+	api.POST("/team/settings", maintainer, h.updateTeamSettings)
+
+	api.POST("/users/setIsActive", maintainer, h.setIsActive)
+	api.GET("/users/getReview", h.getReview)
+	api.POST("/users/setTags", maintainer, h.setTags)
+	api.GET("/users/absences", h.listAbsences)
+	api.POST("/users/absences", maintainer, h.scheduleAbsence)
+	api.POST("/users/absences/cancel", maintainer, h.cancelAbsence)
+	api.POST("/users/setExternalLogin", maintainer, h.setExternalLogin)
+
+	api.POST("/pullRequest/create", ci, h.createPR)
+	api.POST("/pullRequest/merge", ci, h.mergePR)
+	api.POST("/pullRequest/close", ci, h.closePR)
+	api.POST("/pullRequest/reopen", ci, h.reopenPR)
+	api.POST("/pullRequest/ready", ci, h.markReady)
+	api.POST("/pullRequest/reassign", reviewer, h.reassignReviewer)
+	api.POST("/pullRequest/reassignTo", maintainer, h.reassignReviewerTo)
+	api.POST("/pullRequest/addReviewer", maintainer, h.addReviewer)
+	api.POST("/pullRequest/removeReviewer", maintainer, h.removeReviewer)
+	api.POST("/pullRequest/review", reviewer, h.submitReview)
+	api.GET("/pullRequest/reviews", h.getReviews)
+	api.GET("/pullRequest/assignments", h.getAssignments)
+
+	api.GET("/codeowners", h.getCodeOwners)
+	api.POST("/codeowners/import", admin, h.importCodeOwners)
 
-	router.POST("/users/setIsActive", h.setIsActive)
-	router.GET("/users/getReview", h.getReview)
+	api.GET("/webhooks/subscriptions", admin, h.listWebhookSubscriptions)
+	api.POST("/webhooks/subscriptions", admin, h.createWebhookSubscription)
+	api.POST("/webhooks/subscriptions/delete", admin, h.deleteWebhookSubscription)
+	api.GET("/webhooks/deliveries", admin, h.getWebhookDeliveries)
 
-	router.POST("/pullRequest/create", h.createPR)
-	router.POST("/pullRequest/merge", h.mergePR)
-	router.POST("/pullRequest/reassign", h.reassignReviewer)
+	api.GET("/auth/tokens", admin, h.listTokens)
+	api.POST("/auth/tokens", admin, h.issueToken)
+	api.POST("/auth/tokens/revoke", admin, h.revokeToken)
 }
 
 type errorResponse struct {
@@ -36,6 +103,10 @@ type errorDetail struct {
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, code string, msg string) {
+	// Внутренние ошибки попадают в access-лог запроса
+	if statusCode >= http.StatusInternalServerError {
+		c.Error(errors.New(msg))
+	}
 	c.AbortWithStatusJSON(statusCode, errorResponse{
 		Error: errorDetail{
 			Code:    code,
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type prIDRequest struct {
+	ID string `json:"pull_request_id" binding:"required"`
+}
+
+func (h *Handler) closePR(c *gin.Context) {
+	h.changeStatus(c, h.svc.ClosePR)
+}
+
+func (h *Handler) reopenPR(c *gin.Context) {
+	h.changeStatus(c, h.svc.ReopenPR)
+}
+
+func (h *Handler) markReady(c *gin.Context) {
+	h.changeStatus(c, h.svc.MarkReady)
+}
+
+func (h *Handler) changeStatus(c *gin.Context, change func(ctx context.Context, prID string) (*domain.PullRequest, error)) {
+	var req prIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id is required")
+		return
+	}
+	if !h.authorizePR(c, req.ID) {
+		return
+	}
+
+	pr, err := change(c.Request.Context(), req.ID)
+	if err != nil {
+		switch err {
+		case service.ErrPRNotFound:
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "pull request not found")
+		case service.ErrAuthorNotFound:
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "author not found")
+		case service.ErrInvalidTransition:
+			newErrorResponse(c, http.StatusConflict, "INVALID_TRANSITION", "status transition is not allowed")
+		case service.ErrNotEnoughReviewers:
+			newErrorResponse(c, http.StatusConflict, "NOT_ENOUGH_REVIEWERS", "not enough active reviewers in team")
+		case service.ErrNoOwnerAvailable:
+			newErrorResponse(c, http.StatusConflict, "NO_OWNER_AVAILABLE", "no active code owner available for changed files")
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	resp := toPRResponse(pr)
+	resp["fallback_reviewers"] = fallbackIDs(pr)
+	resp["owner_missing"] = pr.OwnerMissing
+	resp["closedAt"] = pr.ClosedAt
+
+	c.JSON(http.StatusOK, gin.H{"pr": resp})
+}
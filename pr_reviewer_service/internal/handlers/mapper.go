@@ -1,6 +1,10 @@
 package handlers
 
-import "pr-reviewer/internal/domain"
+import (
+	"pr-reviewer/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
 
 func toDomainTeam(req createTeamRequest) domain.Team {
 	members := make([]domain.User, len(req.Members))
@@ -9,6 +13,7 @@ func toDomainTeam(req createTeamRequest) domain.Team {
 			ID:       m.UserID,
 			Username: m.Username,
 			IsActive: m.IsActive,
+			Tags:     m.Tags,
 		}
 	}
 
@@ -17,3 +22,39 @@ func toDomainTeam(req createTeamRequest) domain.Team {
 		Members: members,
 	}
 }
+
+func fallbackIDs(pr *domain.PullRequest) []string {
+	if pr.FallbackReviewerIDs == nil {
+		return []string{}
+	}
+	return pr.FallbackReviewerIDs
+}
+
+func toDomainPR(req createPRRequest) domain.PullRequest {
+	pr := domain.PullRequest{
+		ID:           req.ID,
+		Name:         req.Name,
+		AuthorID:     req.AuthorID,
+		ChangedFiles: req.ChangedFiles,
+		Labels:       req.Labels,
+	}
+	if req.Draft {
+		pr.Status = domain.PRStatusDraft
+	}
+	return pr
+}
+
+func toPRResponse(pr *domain.PullRequest) gin.H {
+	reviewerIDs := make([]string, len(pr.Reviewers))
+	for i, r := range pr.Reviewers {
+		reviewerIDs[i] = r.ID
+	}
+
+	return gin.H{
+		"pull_request_id":    pr.ID,
+		"pull_request_name":  pr.Name,
+		"author_id":          pr.AuthorID,
+		"status":             pr.Status,
+		"assigned_reviewers": reviewerIDs,
+	}
+}
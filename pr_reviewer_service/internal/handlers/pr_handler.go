@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/service"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +14,12 @@ type createPRRequest struct {
 	ID       string `json:"pull_request_id" binding:"required"`
 	Name     string `json:"pull_request_name" binding:"required"`
 	AuthorID string `json:"author_id" binding:"required"`
+	// ChangedFiles — пути изменённых файлов для маршрутизации по CODEOWNERS
+	ChangedFiles []string `json:"changed_files"`
+	// Labels — метки PR для подбора ревьюеров по тегам экспертизы
+	Labels []string `json:"labels"`
+	// Draft создаёт черновик: ревьюеры назначаются после /pullRequest/ready
+	Draft bool `json:"draft"`
 }
 
 func (h *Handler) createPR(c *gin.Context) {
@@ -19,38 +28,46 @@ func (h *Handler) createPR(c *gin.Context) {
 		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
 		return
 	}
+	if !h.authorizeUser(c, req.AuthorID) {
+		return
+	}
 
-	pr, err := h.svc.CreatePR(c.Request.Context(), req.ID, req.Name, req.AuthorID)
+	pr, err := h.svc.CreatePR(c.Request.Context(), toDomainPR(req))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidTags) {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+			return
+		}
 		switch err {
 		case service.ErrPRExists:
 			newErrorResponse(c, http.StatusConflict, "PR_EXISTS", "PR id already exists")
 		case service.ErrAuthorNotFound:
 			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "author or team not found")
+		case service.ErrNotEnoughReviewers:
+			newErrorResponse(c, http.StatusConflict, "NOT_ENOUGH_REVIEWERS", "not enough active reviewers in team")
+		case service.ErrNoOwnerAvailable:
+			newErrorResponse(c, http.StatusConflict, "NO_OWNER_AVAILABLE", "no active code owner available for changed files")
 		default:
 			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 		}
 		return
 	}
 
-	reviewerIDs := make([]string, len(pr.Reviewers))
-	for i, r := range pr.Reviewers {
-		reviewerIDs[i] = r.ID
-	}
+	resp := toPRResponse(pr)
+	resp["fallback_reviewers"] = fallbackIDs(pr)
+	resp["owner_missing"] = pr.OwnerMissing
+	resp["labels"] = pr.Labels
 
-	c.JSON(http.StatusCreated, gin.H{
-		"pr": gin.H{
-			"pull_request_id":    pr.ID,
-			"pull_request_name":  pr.Name,
-			"author_id":          pr.AuthorID,
-			"status":             pr.Status,
-			"assigned_reviewers": reviewerIDs,
-		},
-	})
+	c.JSON(http.StatusCreated, gin.H{"pr": resp})
 }
 
 type mergePRRequest struct {
 	ID string `json:"pull_request_id" binding:"required"`
+	// Force сливает PR в обход политики слияния; в журнал попадают Reason
+	// и вызывающий. ForcedBy учитывается, только если аутентификация отключена
+	Force    bool   `json:"force"`
+	ForcedBy string `json:"forced_by"`
+	Reason   string `json:"reason"`
 }
 
 func (h *Handler) mergePR(c *gin.Context) {
@@ -59,37 +76,55 @@ func (h *Handler) mergePR(c *gin.Context) {
 		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id is required")
 		return
 	}
+	if !h.authorizePR(c, req.ID) {
+		return
+	}
+
+	var override *domain.MergeOverride
+	if req.Force {
+		forcedBy := req.ForcedBy
+		if apiToken(c) != nil {
+			forcedBy = callerName(c)
+		}
+		if req.Reason == "" {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "reason is required for force merge")
+			return
+		}
+		if forcedBy == "" {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "forced_by is required for force merge without authentication")
+			return
+		}
+		override = &domain.MergeOverride{ForcedBy: forcedBy, Reason: req.Reason}
+	}
 
-	pr, err := h.svc.MergePR(c.Request.Context(), req.ID)
+	pr, err := h.svc.MergePR(c.Request.Context(), req.ID, override)
 	if err != nil {
 		if err == service.ErrPRNotFound {
 			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "pull request not found")
 			return
 		}
+		if errors.Is(err, service.ErrMergePolicyUnmet) {
+			newErrorResponse(c, http.StatusConflict, "MERGE_POLICY_UNMET", err.Error())
+			return
+		}
+		if err == service.ErrInvalidTransition {
+			newErrorResponse(c, http.StatusConflict, "INVALID_TRANSITION", "only open PR can be merged")
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 		return
 	}
 
-	reviewerIDs := make([]string, len(pr.Reviewers))
-	for i, r := range pr.Reviewers {
-		reviewerIDs[i] = r.ID
-	}
+	resp := toPRResponse(pr)
+	resp["mergedAt"] = pr.MergedAt
 
-	c.JSON(http.StatusOK, gin.H{
-		"pr": gin.H{
-			"pull_request_id":    pr.ID,
-			"pull_request_name":  pr.Name,
-			"author_id":          pr.AuthorID,
-			"status":             pr.Status,
-			"assigned_reviewers": reviewerIDs,
-			"mergedAt":           pr.MergedAt,
-		},
-	})
+	c.JSON(http.StatusOK, gin.H{"pr": resp})
 }
 
 type reassignReviewerRequest struct {
 	PullRequestID string `json:"pull_request_id" binding:"required"`
-	OldUserID     string `json:"old_user_id" binding:"required"`
+	// OldUserID можно не передавать при аутентификации пользователем — тогда это он сам
+	OldUserID string `json:"old_user_id"`
 }
 
 func (h *Handler) reassignReviewer(c *gin.Context) {
@@ -98,38 +133,38 @@ func (h *Handler) reassignReviewer(c *gin.Context) {
 		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
 		return
 	}
+	if !h.authorizeReviewer(c, req.PullRequestID, req.OldUserID) {
+		return
+	}
 
 	pr, newReviewer, err := h.svc.ReassignReviewer(c.Request.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
 		switch err {
+		case service.ErrNoActingUser:
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "old_user_id is required")
 		case service.ErrPRNotFound:
 			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "pull request not found")
 		case service.ErrPRMerged:
 			newErrorResponse(c, http.StatusConflict, "PR_MERGED", "cannot reassign on merged PR")
+		case service.ErrPRNotOpen:
+			newErrorResponse(c, http.StatusConflict, "PR_NOT_OPEN", "pull request is not open")
 		case service.ErrNotAssigned:
 			newErrorResponse(c, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
 		case service.ErrNoCandidate:
 			newErrorResponse(c, http.StatusConflict, "NO_CANDIDATE", "no active replacement candidate in team")
+		case service.ErrNoOwnerAvailable:
+			newErrorResponse(c, http.StatusConflict, "NO_OWNER_AVAILABLE", "no active code owner available for changed files")
 		default:
 			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 		}
 		return
 	}
 
-	reviewerIDs := make([]string, len(pr.Reviewers))
-	for i, r := range pr.Reviewers {
-		reviewerIDs[i] = r.ID
-	}
-
 	c.JSON(http.StatusOK, gin.H{
-		"pr": gin.H{
-			"pull_request_id":    pr.ID,
-			"pull_request_name":  pr.Name,
-			"author_id":          pr.AuthorID,
-			"status":             pr.Status,
-			"assigned_reviewers": reviewerIDs,
-		},
-		"replaced_by": newReviewer.ID,
+		"pr":            toPRResponse(pr),
+		"replaced_by":   newReviewer.ID,
+		"from_fallback": slices.Contains(pr.FallbackReviewerIDs, newReviewer.ID),
+		"owner_missing": pr.OwnerMissing,
 	})
 }
 
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reviewerRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	UserID        string `json:"user_id" binding:"required"`
+}
+
+func (h *Handler) addReviewer(c *gin.Context) {
+	var req reviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizePR(c, req.PullRequestID) {
+		return
+	}
+
+	pr, err := h.svc.AddReviewer(c.Request.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		reviewerErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": toPRResponse(pr)})
+}
+
+func (h *Handler) removeReviewer(c *gin.Context) {
+	var req reviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizePR(c, req.PullRequestID) {
+		return
+	}
+
+	pr, err := h.svc.RemoveReviewer(c.Request.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		reviewerErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": toPRResponse(pr)})
+}
+
+type reassignReviewerToRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	OldUserID     string `json:"old_user_id" binding:"required"`
+	NewUserID     string `json:"new_user_id" binding:"required"`
+}
+
+func (h *Handler) reassignReviewerTo(c *gin.Context) {
+	var req reassignReviewerToRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizePR(c, req.PullRequestID) {
+		return
+	}
+
+	pr, err := h.svc.ReassignReviewerTo(c.Request.Context(), req.PullRequestID, req.OldUserID, req.NewUserID)
+	if err != nil {
+		reviewerErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr":          toPRResponse(pr),
+		"replaced_by": req.NewUserID,
+	})
+}
+
+func reviewerErrorResponse(c *gin.Context, err error) {
+	switch err {
+	case service.ErrPRNotFound:
+		newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "pull request not found")
+	case service.ErrUserNotFound:
+		newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+	case service.ErrAuthorNotFound:
+		newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "author not found")
+	case service.ErrPRMerged:
+		newErrorResponse(c, http.StatusConflict, "PR_MERGED", "cannot change reviewers on merged PR")
+	case service.ErrPRNotOpen:
+		newErrorResponse(c, http.StatusConflict, "PR_NOT_OPEN", "pull request is not open")
+	case service.ErrNotAssigned:
+		newErrorResponse(c, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+	case service.ErrAlreadyAssigned:
+		newErrorResponse(c, http.StatusConflict, "ALREADY_ASSIGNED", "user is already assigned to this PR")
+	case service.ErrReviewerIsAuthor:
+		newErrorResponse(c, http.StatusConflict, "AUTHOR_AS_REVIEWER", "author cannot review own PR")
+	case service.ErrReviewerInactive:
+		newErrorResponse(c, http.StatusConflict, "REVIEWER_INACTIVE", "user is not active or is absent")
+	case service.ErrNotEnoughReviewers:
+		newErrorResponse(c, http.StatusConflict, "NOT_ENOUGH_REVIEWERS", "PR would have fewer reviewers than team minimum")
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
+
+type submitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	// ReviewerID можно не передавать при аутентификации пользователем — тогда это он сам
+	ReviewerID string `json:"reviewer_id"`
+	Decision   string `json:"decision" binding:"required"`
+}
+
+func (h *Handler) submitReview(c *gin.Context) {
+	var req submitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizeReviewer(c, req.PullRequestID, req.ReviewerID) {
+		return
+	}
+
+	review, err := h.svc.SubmitReview(c.Request.Context(), req.PullRequestID, req.ReviewerID, domain.ReviewDecision(req.Decision))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidDecision:
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "decision must be APPROVED, CHANGES_REQUESTED or COMMENTED")
+			return
+		case service.ErrNoActingUser:
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "reviewer_id is required")
+			return
+		}
+		reviewerErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"review": review})
+}
+
+func (h *Handler) getReviews(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id query param is required")
+		return
+	}
+
+	reviews, err := h.svc.GetReviews(c.Request.Context(), prID)
+	if err != nil {
+		reviewerErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pull_request_id": prID,
+		"reviews":         reviews,
+	})
+}
+
+func (h *Handler) getAssignments(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id query param is required")
+		return
+	}
+
+	assignments, err := h.svc.GetAssignments(c.Request.Context(), prID)
+	if err != nil {
+		reviewerErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pull_request_id": prID,
+		"assignments":     assignments,
+	})
+}
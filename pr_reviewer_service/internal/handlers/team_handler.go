@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -10,9 +12,10 @@ import (
 type createTeamRequest struct {
 	TeamName string `json:"team_name" binding:"required"`
 	Members  []struct {
-		UserID   string `json:"user_id" binding:"required"`
-		Username string `json:"username" binding:"required"`
-		IsActive bool   `json:"is_active" binding:"required"`
+		UserID   string   `json:"user_id" binding:"required"`
+		Username string   `json:"username" binding:"required"`
+		IsActive bool     `json:"is_active" binding:"required"`
+		Tags     []string `json:"tags"`
 	} `json:"members"`
 }
 
@@ -26,6 +29,10 @@ func (h *Handler) createTeam(c *gin.Context) {
 
 	err := h.svc.CreateTeam(c.Request.Context(), team)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidTags) {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusBadRequest, "TEAM_EXISTS", err.Error())
 		return
 	}
@@ -52,3 +59,82 @@ func (h *Handler) getTeam(c *gin.Context) {
 
 	c.JSON(http.StatusOK, team)
 }
+
+func (h *Handler) getTeamSettings(c *gin.Context) {
+	name := c.Query("team_name")
+	if name == "" {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "team_name required")
+		return
+	}
+
+	settings, err := h.svc.GetTeamSettings(c.Request.Context(), name)
+	if err != nil {
+		if err == service.ErrTeamNotFound {
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// updateTeamSettingsRequest меняет только переданные поля.
+type updateTeamSettingsRequest struct {
+	TeamName      string   `json:"team_name" binding:"required"`
+	ReviewerCount *int     `json:"reviewer_count"`
+	MinReviewers  *int     `json:"min_reviewers"`
+	Strategy      *string  `json:"strategy"`
+	FallbackTeams []string `json:"fallback_teams"`
+
+	ReassignOnDeactivate *bool `json:"reassign_on_deactivate"`
+	OwnerFallback        *bool `json:"owner_fallback"`
+
+	RequiredApprovals       *int  `json:"required_approvals"`
+	BlockOnChangesRequested *bool `json:"block_on_changes_requested"`
+
+	SLAHours  *int              `json:"sla_hours"`
+	SLAAction *domain.SLAAction `json:"sla_action"`
+}
+
+func (h *Handler) updateTeamSettings(c *gin.Context) {
+	var req updateTeamSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+	if !h.authorizeTeam(c, req.TeamName) {
+		return
+	}
+
+	settings, err := h.svc.UpdateTeamSettings(c.Request.Context(), domain.TeamSettingsUpdate{
+		TeamName:      req.TeamName,
+		ReviewerCount: req.ReviewerCount,
+		MinReviewers:  req.MinReviewers,
+		Strategy:      req.Strategy,
+		FallbackTeams: req.FallbackTeams,
+
+		ReassignOnDeactivate: req.ReassignOnDeactivate,
+		OwnerFallback:        req.OwnerFallback,
+
+		RequiredApprovals:       req.RequiredApprovals,
+		BlockOnChangesRequested: req.BlockOnChangesRequested,
+
+		SLAHours:  req.SLAHours,
+		SLAAction: req.SLAAction,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTeamNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "team not found")
+		case errors.Is(err, service.ErrInvalidSettings):
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
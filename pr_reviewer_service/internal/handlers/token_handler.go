@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type issueTokenRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Role      string     `json:"role" binding:"required"`
+	TeamName  string     `json:"team_name"`
+	UserID    string     `json:"user_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h *Handler) issueToken(c *gin.Context) {
+	var req issueTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+
+	raw, token, err := h.svc.IssueToken(c.Request.Context(), domain.APIToken{
+		Name:      req.Name,
+		Role:      domain.Role(req.Role),
+		TeamName:  req.TeamName,
+		UserID:    req.UserID,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		case errors.Is(err, service.ErrTeamNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "team not found")
+		case errors.Is(err, service.ErrUserNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	// Значение токена больше нигде не отдаётся
+	c.JSON(http.StatusCreated, gin.H{"token": raw, "api_token": token})
+}
+
+func (h *Handler) listTokens(c *gin.Context) {
+	tokens, err := h.svc.ListTokens(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+type revokeTokenRequest struct {
+	TokenID int64 `json:"token_id" binding:"required"`
+}
+
+func (h *Handler) revokeToken(c *gin.Context) {
+	var req revokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+
+	if err := h.svc.RevokeToken(c.Request.Context(), req.TokenID); err != nil {
+		if err == service.ErrTokenNotFound {
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "token not found or already revoked")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/service"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,8 +21,22 @@ func (h *Handler) setIsActive(c *gin.Context) {
 		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input")
 		return
 	}
+	if !h.authorizeUser(c, req.UserID) {
+		return
+	}
+
+	// ?reassign=true|false переопределяет настройку команды reassign_on_deactivate
+	var reassign *bool
+	if raw := c.Query("reassign"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "reassign must be a boolean")
+			return
+		}
+		reassign = &value
+	}
 
-	user, err := h.svc.SetUserActive(c.Request.Context(), req.UserID, *req.IsActive)
+	user, report, err := h.svc.SetUserActive(c.Request.Context(), req.UserID, *req.IsActive, reassign)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
@@ -29,5 +46,76 @@ func (h *Handler) setIsActive(c *gin.Context) {
 		return
 	}
 
+	if report != nil {
+		c.JSON(http.StatusOK, gin.H{"user": user, "reassignment": report})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+type setTagsRequest struct {
+	UserID string   `json:"user_id" binding:"required"`
+	Tags   []string `json:"tags"`
+}
+
+func (h *Handler) setTags(c *gin.Context) {
+	var req setTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input")
+		return
+	}
+	if !h.authorizeUser(c, req.UserID) {
+		return
+	}
+
+	user, err := h.svc.SetUserTags(c.Request.Context(), req.UserID, req.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+		case errors.Is(err, service.ErrInvalidTags):
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
+
+type setExternalLoginRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	Provider string `json:"provider" binding:"required"`
+	Login    string `json:"login" binding:"required"`
+}
+
+func (h *Handler) setExternalLogin(c *gin.Context) {
+	var req setExternalLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input")
+		return
+	}
+	if !h.authorizeUser(c, req.UserID) {
+		return
+	}
+
+	account, err := h.svc.SetExternalLogin(c.Request.Context(), domain.ExternalAccount{
+		Provider: domain.Provider(req.Provider),
+		Login:    req.Login,
+		UserID:   req.UserID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "user not found")
+		case errors.Is(err, service.ErrInvalidExternalAccount):
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"account": account})
+}
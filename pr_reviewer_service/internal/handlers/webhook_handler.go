@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createSubscriptionRequest struct {
+	URL    string             `json:"url" binding:"required"`
+	Secret string             `json:"secret" binding:"required"`
+	Events []domain.EventType `json:"events"`
+}
+
+func (h *Handler) createWebhookSubscription(c *gin.Context) {
+	var req createSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+
+	sub, err := h.svc.CreateWebhookSubscription(c.Request.Context(), domain.WebhookSubscription{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSubscription) {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub})
+}
+
+func (h *Handler) listWebhookSubscriptions(c *gin.Context) {
+	subs, err := h.svc.ListWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+type deleteSubscriptionRequest struct {
+	SubscriptionID int64 `json:"subscription_id" binding:"required"`
+}
+
+func (h *Handler) deleteWebhookSubscription(c *gin.Context) {
+	var req deleteSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid input body")
+		return
+	}
+
+	if err := h.svc.DeleteWebhookSubscription(c.Request.Context(), req.SubscriptionID); err != nil {
+		if err == service.ErrSubscriptionNotFound {
+			newErrorResponse(c, http.StatusNotFound, "NOT_FOUND", "subscription not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) getWebhookDeliveries(c *gin.Context) {
+	var subscriptionID int64
+	if raw := c.Query("subscription_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			newErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "subscription_id must be a positive integer")
+			return
+		}
+		subscriptionID = id
+	}
+
+	deliveries, err := h.svc.GetWebhookDeliveries(c.Request.Context(), subscriptionID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
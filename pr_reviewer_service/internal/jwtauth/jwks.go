@@ -0,0 +1,276 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"pr-reviewer/internal/logging"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// minRefetchInterval ограничивает внеплановую загрузку JWKS при неизвестном kid,
+	// чтобы поток токенов с чужими kid не превращался в поток запросов к провайдеру
+	minRefetchInterval = 30 * time.Second
+	fetchTimeout       = 10 * time.Second
+	maxJWKSSize        = 1 << 20
+)
+
+// KeySet — ключи проверки подписи из JWKS. Ключи перечитываются раз в refresh,
+// а также при встрече неизвестного kid — так подхватывается ротация ключей
+// провайдера. Если перечитать не удалось, продолжают действовать прежние ключи.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+
+	// current — загруженный набор ключей; при перечитывании заменяется
+	// целиком, поэтому проверка подписи не ждёт загрузки JWKS
+	current atomic.Pointer[keySnapshot]
+
+	mu          sync.Mutex
+	attemptedAt time.Time
+	// loading закрывается по окончании идущей загрузки; nil — загрузки нет
+	loading chan struct{}
+}
+
+type keySnapshot struct {
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+// NewKeySet загружает JWKS из файла или по http(s)-URL source.
+func NewKeySet(ctx context.Context, source string, refresh time.Duration) (*KeySet, error) {
+	ks := &KeySet{
+		source:      source,
+		refresh:     refresh,
+		client:      &http.Client{Timeout: fetchTimeout},
+		attemptedAt: time.Now(),
+	}
+	if err := ks.load(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// key возвращает ключ по kid; пустой kid допустим, если ключ в наборе один.
+func (ks *KeySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	snap := ks.current.Load()
+	stale := time.Since(snap.loadedAt) >= ks.refresh
+	if stale {
+		// Устаревшие ключи ещё годятся, поэтому чужую загрузку не ждём
+		ks.reload(ctx, false)
+		snap = ks.current.Load()
+	}
+
+	key, ok := snap.lookup(kid)
+	if !ok && !stale {
+		ks.reload(ctx, true)
+		key, ok = ks.current.Load().lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (snap *keySnapshot) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(snap.keys) == 1 {
+		for _, key := range snap.keys {
+			return key, true
+		}
+	}
+	key, ok := snap.keys[kid]
+	return key, ok
+}
+
+// reload перечитывает JWKS не чаще раза в minRefetchInterval. Одновременные
+// вызовы не идут к провайдеру каждый сам: с wait они дожидаются уже идущей
+// загрузки, без него сразу возвращаются к прежним ключам. ks.mu на время
+// загрузки не удерживается.
+func (ks *KeySet) reload(ctx context.Context, wait bool) {
+	ks.mu.Lock()
+	if done := ks.loading; done != nil {
+		ks.mu.Unlock()
+		if wait {
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+		}
+		return
+	}
+	if time.Since(ks.attemptedAt) < minRefetchInterval {
+		ks.mu.Unlock()
+		return
+	}
+	ks.attemptedAt = time.Now()
+	done := make(chan struct{})
+	ks.loading = done
+	ks.mu.Unlock()
+
+	defer func() {
+		ks.mu.Lock()
+		ks.loading = nil
+		ks.mu.Unlock()
+		close(done)
+	}()
+
+	// Загрузку ждут и другие запросы, поэтому отмена этого её не прерывает;
+	// время ограничено таймаутом клиента
+	if err := ks.load(context.WithoutCancel(ctx)); err != nil {
+		logging.FromContext(ctx).Warn("Failed to refresh JWKS, keeping previous keys",
+			zap.String("source", ks.source), zap.Error(err))
+	}
+}
+
+func (ks *KeySet) load(ctx context.Context) error {
+	startedAt := time.Now()
+
+	body, err := ks.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load JWKS from %s: %w", ks.source, err)
+	}
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return fmt.Errorf("failed to parse JWKS from %s: %w", ks.source, err)
+	}
+
+	ks.current.Store(&keySnapshot{keys: keys, loadedAt: startedAt})
+	return nil
+}
+
+func (ks *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !isURL(ks.source) {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS разбирает ключи подписи RSA и EC P-256; ключи шифрования
+// и прочих типов пропускаются.
+func parseJWKS(body []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k)
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			key, err = ecKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no RSA or P-256 signing keys")
+	}
+	return keys, nil
+}
+
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := decodeInt(k.E)
+	if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	if n.BitLen() < 2048 {
+		return nil, fmt.Errorf("RSA key is too short: %d bits", n.BitLen())
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func ecKey(k jwk) (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != 32 {
+		return nil, errors.New("invalid x")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil || len(y) != 32 {
+		return nil, errors.New("invalid y")
+	}
+	// Разбор несжатой точки заодно проверяет, что она лежит на кривой
+	point := append(append([]byte{4}, x...), y...)
+	return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,156 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func rsaJWK(t *testing.T, kid string) jwk {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// jwksServer отдаёт сначала только первый ключ, а после release — все.
+// Запросы после первого ждут release.
+type jwksServer struct {
+	*httptest.Server
+
+	requests    atomic.Int32
+	started     chan struct{}
+	release     chan struct{}
+	releaseOnce sync.Once
+}
+
+func (s *jwksServer) unblock() {
+	s.releaseOnce.Do(func() { close(s.release) })
+}
+
+func newJWKSServer(t *testing.T, initial jwk, rotated ...jwk) *jwksServer {
+	s := &jwksServer{started: make(chan struct{}, 16), release: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []jwk{initial}
+		if s.requests.Add(1) > 1 {
+			s.started <- struct{}{}
+			<-s.release
+			keys = append(keys, rotated...)
+		}
+		json.NewEncoder(w).Encode(map[string][]jwk{"keys": keys})
+	}))
+	// Отпускаем зависшие запросы до закрытия сервера, даже если тест упал
+	t.Cleanup(s.Close)
+	t.Cleanup(s.unblock)
+	return s
+}
+
+func newTestKeySet(t *testing.T, source string) *KeySet {
+	t.Helper()
+	ks, err := NewKeySet(context.Background(), source, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Снимаем ограничение частоты, чтобы неизвестный kid сразу вызвал загрузку
+	ks.attemptedAt = time.Time{}
+	return ks
+}
+
+func TestKnownKeyDoesNotWaitForRefetch(t *testing.T) {
+	srv := newJWKSServer(t, rsaJWK(t, "k1"), rsaJWK(t, "k2"))
+	ks := newTestKeySet(t, srv.URL)
+	ctx := context.Background()
+
+	rotated := make(chan error, 1)
+	go func() {
+		_, err := ks.key(ctx, "k2")
+		rotated <- err
+	}()
+	<-srv.started
+
+	// Пока провайдер не ответил, известный ключ выдаётся без ожидания
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := ks.key(ctx, "k1")
+		lookup <- err
+	}()
+	select {
+	case err := <-lookup:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookup of a known key waited for the JWKS refetch")
+	}
+
+	srv.unblock()
+	if err := <-rotated; err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+}
+
+func TestConcurrentRefetchIsShared(t *testing.T) {
+	srv := newJWKSServer(t, rsaJWK(t, "k1"), rsaJWK(t, "k2"))
+	ks := newTestKeySet(t, srv.URL)
+	ctx := context.Background()
+
+	const callers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ks.key(ctx, "k2")
+			errs <- err
+		}()
+	}
+
+	<-srv.started
+	// Даём остальным вызовам дойти до ожидания идущей загрузки
+	time.Sleep(50 * time.Millisecond)
+	srv.unblock()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := srv.requests.Load(); n != 2 {
+		t.Errorf("JWKS fetched %d times, want 2 (initial load and one refetch)", n)
+	}
+}
+
+func TestUnknownKeyRefetchIsThrottled(t *testing.T) {
+	srv := newJWKSServer(t, rsaJWK(t, "k1"))
+	srv.unblock()
+	ks := newTestKeySet(t, srv.URL)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := ks.key(ctx, "unknown"); err == nil {
+			t.Fatal("got a key for an unknown kid")
+		}
+	}
+	if n := srv.requests.Load(); n != 2 {
+		t.Errorf("JWKS fetched %d times, want 2 (initial load and one refetch)", n)
+	}
+}
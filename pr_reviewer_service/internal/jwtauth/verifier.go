@@ -0,0 +1,85 @@
+// Package jwtauth проверяет JWT, выпущенные OIDC-провайдером (например,
+// шлюзом перед сервисом), по ключам из JWKS.
+package jwtauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// leeway — допустимое расхождение часов сервиса и провайдера при проверке exp и nbf
+const leeway = 30 * time.Second
+
+var ErrInvalidToken = errors.New("invalid JWT")
+
+type Config struct {
+	// JWKS — путь к файлу или http(s)-URL набора ключей
+	JWKS string
+	// Refresh — как часто перечитывать JWKS
+	Refresh time.Duration
+	// Issuer и Audience — ожидаемые iss и aud; пустые не проверяются
+	Issuer   string
+	Audience string
+	// UserClaim — claim с id пользователя сервиса
+	UserClaim string
+}
+
+type Verifier struct {
+	keys      *KeySet
+	parser    *jwt.Parser
+	userClaim string
+}
+
+// NewVerifier загружает JWKS; ошибка загрузки не даёт сервису стартовать.
+func NewVerifier(ctx context.Context, cfg Config) (*Verifier, error) {
+	keys, err := NewKeySet(ctx, cfg.JWKS, cfg.Refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{
+		keys:      keys,
+		parser:    jwt.NewParser(opts...),
+		userClaim: cfg.UserClaim,
+	}, nil
+}
+
+// LooksLikeJWT отличает JWT (три части через точку) от прочих bearer-токенов.
+func LooksLikeJWT(raw string) bool {
+	return strings.Count(raw, ".") == 2
+}
+
+// Verify проверяет подпись и claims токена и возвращает id пользователя из UserClaim.
+func (v *Verifier) Verify(ctx context.Context, raw string) (string, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userID, _ := claims[v.userClaim].(string)
+	if userID == "" {
+		return "", fmt.Errorf("%w: claim %q is missing or not a string", ErrInvalidToken, v.userClaim)
+	}
+	return userID, nil
+}
@@ -0,0 +1,155 @@
+// Package metrics содержит метрики сервиса в формате Prometheus.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "pr_reviewer"
+
+// registry отделён от глобального, чтобы /metrics отдавал только метрики сервиса.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	PRsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_created_total",
+		Help:      "Pull requests created.",
+	})
+
+	PRsMerged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_merged_total",
+		Help:      "Pull requests merged.",
+	})
+
+	PRTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_request_transitions_total",
+		Help:      "Pull request status changes by ready, close and reopen.",
+	}, []string{"from", "to"})
+
+	ReviewersReassigned = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_reassigned_total",
+		Help:      "Reviewers replaced on pull requests, automatically or explicitly.",
+	})
+
+	ReviewersAdded = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_added_total",
+		Help:      "Reviewers added to pull requests manually.",
+	})
+
+	ReviewersRemoved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_removed_total",
+		Help:      "Reviewers removed from pull requests manually.",
+	})
+
+	NoCandidate = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Reassignments that failed because no replacement candidate was found.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		PRsCreated,
+		PRsMerged,
+		PRTransitions,
+		ReviewersReassigned,
+		ReviewersAdded,
+		ReviewersRemoved,
+		NoCandidate,
+	)
+}
+
+// RegisterDB добавляет статистику пула соединений и число открытых ревью
+// на пользователя; openReviews вызывается при каждом сборе метрик.
+func RegisterDB(db *sql.DB, dbName string, openReviews func(ctx context.Context) (map[string]int, error)) {
+	registry.MustRegister(&openReviewsCollector{count: openReviews})
+	// Для хранилища в памяти пула соединений нет
+	if db != nil {
+		registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+	}
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Middleware считает запросы и их длительность по шаблону маршрута,
+// чтобы параметры запроса не раздували число рядов.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+var openReviewsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_reviews"),
+	"Open pull requests currently assigned to the reviewer.",
+	[]string{"user_id"}, nil,
+)
+
+type openReviewsCollector struct {
+	count func(ctx context.Context) (map[string]int, error)
+}
+
+func (c *openReviewsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openReviewsDesc
+}
+
+func (c *openReviewsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		zap.L().Error("Failed to collect open reviews", zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(openReviewsDesc, err)
+		return
+	}
+	for userID, n := range counts {
+		ch <- prometheus.MustNewConstMetric(openReviewsDesc, prometheus.GaugeValue, float64(n), userID)
+	}
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+)
+
+type CodeOwnersRepo struct{}
+
+func NewCodeOwnersRepo() *CodeOwnersRepo {
+	return &CodeOwnersRepo{}
+}
+
+func (r *CodeOwnersRepo) ReplaceRules(ctx context.Context, db repository.Querier, rules []domain.OwnershipRule) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	d.rules = make([]domain.OwnershipRule, len(rules))
+	for i, rule := range rules {
+		// Владельцы упорядочены и без повторов, как при чтении из PostgreSQL
+		owners := slices.Clone(rule.Owners)
+		slices.SortFunc(owners, func(a, b domain.Owner) int {
+			if c := strings.Compare(string(a.Type), string(b.Type)); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+		rule.Owners = slices.Compact(owners)
+		d.rules[i] = rule
+	}
+	return nil
+}
+
+func (r *CodeOwnersRepo) ListRules(ctx context.Context, db repository.Querier) ([]domain.OwnershipRule, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rules := make([]domain.OwnershipRule, len(d.rules))
+	for i, rule := range d.rules {
+		rule.Owners = append([]domain.Owner{}, rule.Owners...)
+		rules[i] = rule
+	}
+	return rules, nil
+}
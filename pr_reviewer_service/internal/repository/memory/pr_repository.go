@@ -0,0 +1,420 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+type PRRepo struct{}
+
+func NewPRRepo() *PRRepo {
+	return &PRRepo{}
+}
+
+func (r *PRRepo) Exists(ctx context.Context, db repository.Querier, id string) (bool, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	_, ok := d.prs[id]
+	return ok, nil
+}
+
+func (r *PRRepo) Create(ctx context.Context, db repository.Querier, pr domain.PullRequest) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if _, ok := d.prs[pr.ID]; ok {
+		return fmt.Errorf("failed to insert PR: %w", domain.ErrAlreadyExists)
+	}
+	// Ограничения CHECK и перечисления SQL-схемы
+	if !pr.Status.Valid() {
+		return fmt.Errorf("failed to insert PR: invalid status %q", pr.Status)
+	}
+	if _, ok := d.users[pr.AuthorID]; !ok {
+		return fmt.Errorf("failed to insert PR: author %s: %w", pr.AuthorID, domain.ErrNotFound)
+	}
+
+	now := time.Now()
+	row := prRow{
+		pr: domain.PullRequest{
+			ID:           pr.ID,
+			Name:         pr.Name,
+			AuthorID:     pr.AuthorID,
+			Status:       pr.Status,
+			CreatedAt:    now,
+			Labels:       slices.Compact(slices.Sorted(slices.Values(pr.Labels))),
+			ChangedFiles: slices.Compact(slices.Sorted(slices.Values(pr.ChangedFiles))),
+		},
+	}
+	for _, reviewer := range pr.Reviewers {
+		if _, ok := d.users[reviewer.ID]; !ok {
+			return fmt.Errorf("failed to insert reviewer %s: %w", reviewer.ID, domain.ErrNotFound)
+		}
+		if row.hasReviewer(reviewer.ID) {
+			return fmt.Errorf("failed to insert reviewer %s: %w", reviewer.ID, domain.ErrAlreadyExists)
+		}
+		row.reviewers = append(row.reviewers, assignment{reviewerID: reviewer.ID, assignedAt: now})
+	}
+	d.prs[pr.ID] = row
+	return nil
+}
+
+func (r *PRRepo) GetByID(ctx context.Context, db repository.Querier, id string) (*domain.PullRequest, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	row, ok := d.prs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	pr := row.pr
+	pr.Labels = slices.Clone(pr.Labels)
+	pr.ChangedFiles = slices.Clone(pr.ChangedFiles)
+	for _, a := range row.reviewers {
+		u := d.users[a.reviewerID]
+		pr.Reviewers = append(pr.Reviewers, domain.User{
+			ID:       u.ID,
+			Username: u.Username,
+			IsActive: u.IsActive,
+			TeamName: u.TeamName,
+		})
+	}
+	return &pr, nil
+}
+
+func (r *PRRepo) SetStatus(ctx context.Context, db repository.Querier, id string, status domain.PRStatus) error {
+	if !status.Valid() {
+		return fmt.Errorf("failed to update PR status: invalid status %q", status)
+	}
+	return r.update(ctx, db, id, func(row *prRow) error {
+		now := time.Now()
+		row.pr.Status = status
+		switch status {
+		case domain.PRStatusMerged:
+			row.pr.MergedAt = &now
+		case domain.PRStatusClosed:
+			row.pr.ClosedAt = &now
+		default:
+			row.pr.ClosedAt = nil
+		}
+		return nil
+	})
+}
+
+func (r *PRRepo) ReplaceReviewer(ctx context.Context, db repository.Querier, prID, oldReviewerID, newReviewerID string) error {
+	if err := r.RemoveReviewer(ctx, db, prID, oldReviewerID); err != nil {
+		return err
+	}
+	return r.AddReviewer(ctx, db, prID, newReviewerID)
+}
+
+func (r *PRRepo) AddReviewer(ctx context.Context, db repository.Querier, prID, reviewerID string) error {
+	return r.update(ctx, db, prID, func(row *prRow) error {
+		if row.hasReviewer(reviewerID) {
+			return fmt.Errorf("failed to insert reviewer %s: %w", reviewerID, domain.ErrAlreadyExists)
+		}
+		row.reviewers = append(row.reviewers, assignment{reviewerID: reviewerID, assignedAt: time.Now()})
+		return nil
+	})
+}
+
+func (r *PRRepo) RemoveReviewer(ctx context.Context, db repository.Querier, prID, reviewerID string) error {
+	return r.update(ctx, db, prID, func(row *prRow) error {
+		row.reviewers = slices.DeleteFunc(row.reviewers, func(a assignment) bool {
+			return a.reviewerID == reviewerID
+		})
+		return nil
+	})
+}
+
+// GetByReviewerID возвращает открытые PR, на которые назначен ревьюер.
+func (r *PRRepo) GetByReviewerID(ctx context.Context, db repository.Querier, reviewerID string) ([]domain.PullRequestShort, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	result := []domain.PullRequestShort{}
+	for _, row := range d.sortedPRs() {
+		if row.pr.Status == domain.PRStatusOpen && row.hasReviewer(reviewerID) {
+			result = append(result, domain.PullRequestShort{
+				ID:       row.pr.ID,
+				Name:     row.pr.Name,
+				AuthorID: row.pr.AuthorID,
+				Status:   row.pr.Status,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (r *PRRepo) AddReview(ctx context.Context, db repository.Querier, review domain.Review) (*domain.Review, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	row, ok := d.prs[review.PullRequestID]
+	if !ok {
+		return nil, fmt.Errorf("failed to insert review: PR %s: %w", review.PullRequestID, domain.ErrNotFound)
+	}
+	if !review.Decision.Valid() {
+		return nil, fmt.Errorf("failed to insert review: invalid decision %q", review.Decision)
+	}
+
+	review.CreatedAt = time.Now()
+	d.reviews = append(d.reviews, review)
+
+	row.reviewers = slices.Clone(row.reviewers)
+	for i := range row.reviewers {
+		a := &row.reviewers[i]
+		if a.reviewerID == review.ReviewerID && a.firstActionAt == nil {
+			at := review.CreatedAt
+			a.firstActionAt = &at
+		}
+	}
+	d.prs[review.PullRequestID] = row
+	return &review, nil
+}
+
+// GetReviews возвращает историю решений по PR в хронологическом порядке.
+func (r *PRRepo) GetReviews(ctx context.Context, db repository.Querier, prID string) ([]domain.Review, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	reviews := []domain.Review{}
+	for _, rv := range d.reviews {
+		if rv.PullRequestID == prID {
+			reviews = append(reviews, rv)
+		}
+	}
+	return reviews, nil
+}
+
+func (r *PRRepo) AddMergeOverride(ctx context.Context, db repository.Querier, override domain.MergeOverride) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if _, ok := d.prs[override.PullRequestID]; !ok {
+		return fmt.Errorf("failed to insert merge override: PR %s: %w", override.PullRequestID, domain.ErrNotFound)
+	}
+	override.CreatedAt = time.Now()
+	d.overrides = append(d.overrides, override)
+	return nil
+}
+
+func (r *PRRepo) GetAssignments(ctx context.Context, db repository.Querier, prID string, defaultSLA domain.SLAPolicy) ([]domain.ReviewAssignment, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	assignments := []domain.ReviewAssignment{}
+	row, ok := d.prs[prID]
+	if !ok {
+		return assignments, nil
+	}
+
+	teamName := d.users[row.pr.AuthorID].TeamName
+	sla := d.teamSLA(teamName, defaultSLA)
+	for _, a := range row.reviewers {
+		assignments = append(assignments, domain.ReviewAssignment{
+			PullRequestID: prID,
+			ReviewerID:    a.reviewerID,
+			AssignedAt:    a.assignedAt,
+			FirstActionAt: a.firstActionAt,
+			TeamName:      teamName,
+			SLAHours:      sla.Hours,
+			SLAAction:     sla.Action,
+		})
+	}
+	sortAssignments(assignments)
+	return assignments, nil
+}
+
+// GetOverdueAssignments возвращает назначения на открытые PR, по которым ревьюер
+// не отреагировал дольше SLA команды автора и которые ещё не эскалировались.
+// Для команд без сохранённых настроек действует defaultSLA.
+func (r *PRRepo) GetOverdueAssignments(ctx context.Context, db repository.Querier, defaultSLA domain.SLAPolicy) ([]domain.ReviewAssignment, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	now := time.Now()
+	assignments := []domain.ReviewAssignment{}
+	for _, row := range d.prs {
+		if row.pr.Status != domain.PRStatusOpen {
+			continue
+		}
+		teamName := d.users[row.pr.AuthorID].TeamName
+		sla := d.teamSLA(teamName, defaultSLA)
+		if sla.Hours <= 0 {
+			continue
+		}
+
+		deadline := time.Duration(sla.Hours) * time.Hour
+		for _, a := range row.reviewers {
+			if a.firstActionAt != nil || a.escalatedAt != nil || !a.assignedAt.Add(deadline).Before(now) {
+				continue
+			}
+			assignments = append(assignments, domain.ReviewAssignment{
+				PullRequestID: row.pr.ID,
+				ReviewerID:    a.reviewerID,
+				AssignedAt:    a.assignedAt,
+				TeamName:      teamName,
+				SLAHours:      sla.Hours,
+				SLAAction:     sla.Action,
+			})
+		}
+	}
+	sortAssignments(assignments)
+	return assignments, nil
+}
+
+// MarkEscalated отмечает назначение эскалированным, только если оно ещё не
+// эскалировано и ревьюер не ответил; false означает, что его уже забрали.
+func (r *PRRepo) MarkEscalated(ctx context.Context, db repository.Querier, prID, reviewerID string) (bool, error) {
+	claimed := false
+	err := r.update(ctx, db, prID, func(row *prRow) error {
+		now := time.Now()
+		for i := range row.reviewers {
+			a := &row.reviewers[i]
+			if a.reviewerID == reviewerID && a.escalatedAt == nil && a.firstActionAt == nil {
+				a.escalatedAt = &now
+				claimed = true
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// RestartAssignments заново отсчитывает SLA для ревьюеров, ещё не ответивших по PR.
+func (r *PRRepo) RestartAssignments(ctx context.Context, db repository.Querier, prID string) error {
+	return r.update(ctx, db, prID, func(row *prRow) error {
+		now := time.Now()
+		for i := range row.reviewers {
+			if row.reviewers[i].firstActionAt == nil {
+				row.reviewers[i].assignedAt = now
+				row.reviewers[i].escalatedAt = nil
+			}
+		}
+		return nil
+	})
+}
+
+// CountOpenReviews возвращает число открытых PR на ревью у каждого ревьюера.
+func (r *PRRepo) CountOpenReviews(ctx context.Context, db repository.Querier) (map[string]int, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return d.openReviews(), nil
+}
+
+// update применяет fn к копии строки PR и сохраняет её, если fn не вернула ошибку.
+// Изменение отсутствующего PR ничего не делает, как UPDATE без подходящих строк.
+func (r *PRRepo) update(ctx context.Context, db repository.Querier, prID string, fn func(row *prRow) error) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	row, ok := d.prs[prID]
+	if !ok {
+		return nil
+	}
+	row.reviewers = slices.Clone(row.reviewers)
+	if err := fn(&row); err != nil {
+		return err
+	}
+	d.prs[prID] = row
+	return nil
+}
+
+func (row prRow) hasReviewer(userID string) bool {
+	return slices.ContainsFunc(row.reviewers, func(a assignment) bool {
+		return a.reviewerID == userID
+	})
+}
+
+// teamSLA возвращает SLA из сохранённых настроек команды или defaultSLA.
+func (d *data) teamSLA(teamName string, defaultSLA domain.SLAPolicy) domain.SLAPolicy {
+	sla := defaultSLA
+	// Незаданные поля, как NULL в БД, берутся из defaultSLA
+	if settings := d.teams[teamName].settings; settings != nil {
+		if settings.SLAHours != nil {
+			sla.Hours = *settings.SLAHours
+		}
+		if settings.SLAAction != "" {
+			sla.Action = settings.SLAAction
+		}
+	}
+	return sla
+}
+
+// sortedPRs возвращает PR в порядке создания.
+func (d *data) sortedPRs() []prRow {
+	rows := make([]prRow, 0, len(d.prs))
+	for _, row := range d.prs {
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b prRow) int {
+		if c := a.pr.CreatedAt.Compare(b.pr.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.pr.ID, b.pr.ID)
+	})
+	return rows
+}
+
+func (d *data) openReviews() map[string]int {
+	result := make(map[string]int)
+	for _, row := range d.prs {
+		if row.pr.Status != domain.PRStatusOpen {
+			continue
+		}
+		for _, a := range row.reviewers {
+			result[a.reviewerID]++
+		}
+	}
+	return result
+}
+
+func sortAssignments(assignments []domain.ReviewAssignment) {
+	slices.SortStableFunc(assignments, func(a, b domain.ReviewAssignment) int {
+		if c := a.AssignedAt.Compare(b.AssignedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.PullRequestID, b.PullRequestID)
+	})
+}
@@ -0,0 +1,232 @@
+// Package memory реализует репозитории в памяти процесса — для локального
+// запуска без PostgreSQL и для тестов сервиса. Данные теряются при остановке.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"time"
+)
+
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// Store — хранилище в памяти. Транзакции выполняются строго по одной:
+// BeginTx захватывает хранилище до Commit или Rollback и запоминает снимок
+// данных, который Rollback восстанавливает. Запросы вне транзакции
+// захватывают хранилище на время одного вызова репозитория.
+type Store struct {
+	// lock — семафор на одно место, захват можно прервать отменой контекста
+	lock chan struct{}
+	data *data
+}
+
+func NewStore() *Store {
+	return &Store{
+		lock: make(chan struct{}, 1),
+		data: newData(),
+	}
+}
+
+func (s *Store) acquire(ctx context.Context) error {
+	select {
+	case s.lock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Store) release() {
+	<-s.lock
+}
+
+const backend = "memory"
+
+func (s *Store) Backend() string {
+	return backend
+}
+
+func (s *Store) BeginTx(ctx context.Context) (repository.Tx, error) {
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return &Tx{store: s, snapshot: s.data.clone()}, nil
+}
+
+type Tx struct {
+	store    *Store
+	snapshot *data
+	done     bool
+}
+
+func (tx *Tx) Backend() string {
+	return backend
+}
+
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	tx.snapshot = nil
+	tx.store.release()
+	return nil
+}
+
+// Rollback после Commit ничего не делает.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+	tx.store.data = tx.snapshot
+	tx.snapshot = nil
+	tx.store.release()
+	return nil
+}
+
+// use возвращает данные, с которыми работает вызов репозитория через db,
+// и функцию, которую нужно вызвать по его завершении.
+func use(ctx context.Context, db repository.Querier) (*data, func(), error) {
+	switch db := db.(type) {
+	case *Tx:
+		if db.done {
+			return nil, nil, ErrTxDone
+		}
+		return db.store.data, func() {}, nil
+	case *Store:
+		if err := db.acquire(ctx); err != nil {
+			return nil, nil, err
+		}
+		return db.data, db.release, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s repository got %T", repository.ErrUnsupportedQuerier, backend, db)
+	}
+}
+
+// data — таблицы хранилища. Строки хранятся по значению; clone копирует
+// и вложенные срезы, поэтому изменения после снимка его не затрагивают.
+type data struct {
+	teams     map[string]teamRow
+	users     map[string]domain.User
+	absences  []domain.Absence
+	externals map[externalKey]string
+
+	prs       map[string]prRow
+	reviews   []domain.Review
+	overrides []domain.MergeOverride
+
+	rules []domain.OwnershipRule
+
+	subscriptions []domain.WebhookSubscription
+	events        []outboxEvent
+	deliveries    []domain.WebhookDelivery
+
+	tokens []tokenRow
+
+	// последние выданные идентификаторы
+	absenceSeq      int64
+	subscriptionSeq int64
+	eventSeq        int64
+	deliverySeq     int64
+	tokenSeq        int64
+}
+
+type teamRow struct {
+	settings  *domain.TeamSettings
+	fallbacks []string
+}
+
+type externalKey struct {
+	provider domain.Provider
+	login    string
+}
+
+type prRow struct {
+	pr        domain.PullRequest
+	reviewers []assignment
+}
+
+// assignment — назначение ревьюера на PR в порядке назначения.
+type assignment struct {
+	reviewerID    string
+	assignedAt    time.Time
+	firstActionAt *time.Time
+	escalatedAt   *time.Time
+}
+
+type tokenRow struct {
+	token domain.APIToken
+	hash  string
+}
+
+type outboxEvent struct {
+	id           int64
+	eventType    domain.EventType
+	payload      []byte
+	createdAt    time.Time
+	dispatchedAt *time.Time
+}
+
+func newData() *data {
+	return &data{
+		teams:     make(map[string]teamRow),
+		users:     make(map[string]domain.User),
+		externals: make(map[externalKey]string),
+		prs:       make(map[string]prRow),
+	}
+}
+
+func (d *data) clone() *data {
+	c := *d
+
+	c.teams = make(map[string]teamRow, len(d.teams))
+	for name, row := range d.teams {
+		if row.settings != nil {
+			settings := *row.settings
+			row.settings = &settings
+		}
+		row.fallbacks = slices.Clone(row.fallbacks)
+		c.teams[name] = row
+	}
+
+	c.users = make(map[string]domain.User, len(d.users))
+	for id, u := range d.users {
+		u.Tags = slices.Clone(u.Tags)
+		c.users[id] = u
+	}
+	c.absences = slices.Clone(d.absences)
+	c.externals = maps.Clone(d.externals)
+
+	c.prs = make(map[string]prRow, len(d.prs))
+	for id, row := range d.prs {
+		row.pr.Labels = slices.Clone(row.pr.Labels)
+		row.pr.ChangedFiles = slices.Clone(row.pr.ChangedFiles)
+		row.reviewers = slices.Clone(row.reviewers)
+		c.prs[id] = row
+	}
+	c.reviews = slices.Clone(d.reviews)
+	c.overrides = slices.Clone(d.overrides)
+
+	c.rules = make([]domain.OwnershipRule, len(d.rules))
+	for i, rule := range d.rules {
+		rule.Owners = slices.Clone(rule.Owners)
+		c.rules[i] = rule
+	}
+
+	c.subscriptions = make([]domain.WebhookSubscription, len(d.subscriptions))
+	for i, sub := range d.subscriptions {
+		sub.Events = slices.Clone(sub.Events)
+		c.subscriptions[i] = sub
+	}
+	c.events = slices.Clone(d.events)
+	c.deliveries = slices.Clone(d.deliveries)
+	c.tokens = slices.Clone(d.tokens)
+
+	return &c
+}
@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"pr-reviewer/internal/repository/memory"
+	"pr-reviewer/internal/repository/repotest"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Storage {
+		return repotest.Storage{
+			DB:     memory.NewStore(),
+			Teams:  memory.NewTeamRepo(),
+			Users:  memory.NewUserRepo(),
+			PRs:    memory.NewPRRepo(),
+			Hooks:  memory.NewWebhookRepo(),
+			Tokens: memory.NewTokenRepo(),
+			Owners: memory.NewCodeOwnersRepo(),
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+)
+
+type TeamRepo struct{}
+
+func NewTeamRepo() *TeamRepo {
+	return &TeamRepo{}
+}
+
+func (r *TeamRepo) Create(ctx context.Context, db repository.Querier, team domain.Team) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if _, ok := d.teams[team.Name]; ok {
+		return fmt.Errorf("failed to insert team: %w", domain.ErrAlreadyExists)
+	}
+	d.teams[team.Name] = teamRow{}
+	return nil
+}
+
+func (r *TeamRepo) GetByName(ctx context.Context, db repository.Querier, name string) (*domain.Team, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if _, ok := d.teams[name]; !ok {
+		return nil, nil
+	}
+
+	team := domain.Team{Name: name}
+	for _, u := range d.users {
+		if u.TeamName == name {
+			team.Members = append(team.Members, copyUser(u))
+		}
+	}
+	slices.SortFunc(team.Members, func(a, b domain.User) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return &team, nil
+}
+
+func (r *TeamRepo) Exists(ctx context.Context, db repository.Querier, name string) (bool, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	_, ok := d.teams[name]
+	return ok, nil
+}
+
+func (r *TeamRepo) GetSettings(ctx context.Context, db repository.Querier, name string) (*domain.TeamSettings, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	row, ok := d.teams[name]
+	if !ok || row.settings == nil {
+		return nil, nil
+	}
+	settings := *row.settings
+	if settings.SLAHours != nil {
+		hours := *settings.SLAHours
+		settings.SLAHours = &hours
+	}
+	return &settings, nil
+}
+
+func (r *TeamRepo) UpsertSettings(ctx context.Context, db repository.Querier, settings domain.TeamSettings) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	row, ok := d.teams[settings.TeamName]
+	if !ok {
+		return fmt.Errorf("failed to upsert team settings: team %s: %w", settings.TeamName, domain.ErrNotFound)
+	}
+	if settings.SLAAction != "" && !settings.SLAAction.Valid() {
+		return fmt.Errorf("failed to upsert team settings: invalid sla_action %q", settings.SLAAction)
+	}
+	// Резервные команды хранятся отдельно, как в team_fallbacks
+	settings.FallbackTeams = nil
+	if settings.SLAHours != nil {
+		hours := *settings.SLAHours
+		settings.SLAHours = &hours
+	}
+	row.settings = &settings
+	d.teams[settings.TeamName] = row
+	return nil
+}
+
+func (r *TeamRepo) GetFallbacks(ctx context.Context, db repository.Querier, name string) ([]string, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	fallbacks := []string{}
+	return append(fallbacks, d.teams[name].fallbacks...), nil
+}
+
+func (r *TeamRepo) SetFallbacks(ctx context.Context, db repository.Querier, name string, fallbackTeams []string) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	row, ok := d.teams[name]
+	if !ok {
+		return fmt.Errorf("failed to set fallback teams: team %s: %w", name, domain.ErrNotFound)
+	}
+	for _, fallback := range fallbackTeams {
+		if _, ok := d.teams[fallback]; !ok || fallback == name {
+			return fmt.Errorf("failed to insert fallback team %s: %w", fallback, domain.ErrConflict)
+		}
+	}
+	row.fallbacks = slices.Clone(fallbackTeams)
+	d.teams[name] = row
+	return nil
+}
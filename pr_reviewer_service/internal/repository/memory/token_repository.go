@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"time"
+)
+
+type TokenRepo struct{}
+
+func NewTokenRepo() *TokenRepo {
+	return &TokenRepo{}
+}
+
+func (r *TokenRepo) Create(ctx context.Context, db repository.Querier, token domain.APIToken, hash string) (*domain.APIToken, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if _, ok := d.teams[token.TeamName]; token.TeamName != "" && !ok {
+		return nil, fmt.Errorf("failed to insert API token: team %s: %w", token.TeamName, domain.ErrNotFound)
+	}
+	if _, ok := d.users[token.UserID]; token.UserID != "" && !ok {
+		return nil, fmt.Errorf("failed to insert API token: user %s: %w", token.UserID, domain.ErrNotFound)
+	}
+	for _, row := range d.tokens {
+		if row.hash == hash {
+			return nil, fmt.Errorf("failed to insert API token: %w", domain.ErrAlreadyExists)
+		}
+	}
+
+	d.tokenSeq++
+	token.ID = d.tokenSeq
+	token.CreatedAt = time.Now()
+	token.RevokedAt = nil
+	d.tokens = append(d.tokens, tokenRow{token: token, hash: hash})
+	return &token, nil
+}
+
+func (r *TokenRepo) GetByHash(ctx context.Context, db repository.Querier, hash string) (*domain.APIToken, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	for _, row := range d.tokens {
+		if row.hash == hash {
+			token := row.token
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *TokenRepo) List(ctx context.Context, db repository.Querier) ([]domain.APIToken, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	tokens := make([]domain.APIToken, len(d.tokens))
+	for i, row := range d.tokens {
+		tokens[i] = row.token
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен; false — токена нет или он уже отозван.
+func (r *TokenRepo) Revoke(ctx context.Context, db repository.Querier, id int64) (bool, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	for i := range d.tokens {
+		token := &d.tokens[i].token
+		if token.ID == id && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
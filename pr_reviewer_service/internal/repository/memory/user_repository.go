@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// copyUser возвращает пользователя с собственной копией тегов,
+// чтобы вызывающий код не мог изменить данные хранилища.
+func copyUser(u domain.User) domain.User {
+	u.Tags = append([]string{}, u.Tags...)
+	return u
+}
+
+type UserRepo struct{}
+
+func NewUserRepo() *UserRepo {
+	return &UserRepo{}
+}
+
+func (r *UserRepo) Upsert(ctx context.Context, db repository.Querier, users []domain.User) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	for _, u := range users {
+		if _, ok := d.teams[u.TeamName]; !ok {
+			return fmt.Errorf("failed to upsert user %s: team %s: %w", u.ID, u.TeamName, domain.ErrNotFound)
+		}
+	}
+	for _, u := range users {
+		// Теги меняются только через SetTags
+		u.Tags = d.users[u.ID].Tags
+		d.users[u.ID] = u
+	}
+	return nil
+}
+
+func (r *UserRepo) SetIsActive(ctx context.Context, db repository.Querier, userID string, isActive bool) (*domain.User, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	u, ok := d.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	u.IsActive = isActive
+	d.users[userID] = u
+
+	u = copyUser(u)
+	return &u, nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, db repository.Querier, userID string) (*domain.User, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	u, ok := d.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	u = copyUser(u)
+	return &u, nil
+}
+
+func (r *UserRepo) GetActiveCandidates(ctx context.Context, db repository.Querier, teamName string, excludeUserIDs []string) ([]domain.Candidate, error) {
+	return r.candidates(ctx, db, func(u domain.User) bool {
+		return u.TeamName == teamName && !slices.Contains(excludeUserIDs, u.ID)
+	})
+}
+
+func (r *UserRepo) GetActiveCandidatesByIDs(ctx context.Context, db repository.Querier, userIDs []string, excludeUserIDs []string) ([]domain.Candidate, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.candidates(ctx, db, func(u domain.User) bool {
+		return slices.Contains(userIDs, u.ID) && !slices.Contains(excludeUserIDs, u.ID)
+	})
+}
+
+// candidates отбирает активных и не отсутствующих сейчас пользователей,
+// подходящих под match, и считает их открытые ревью.
+func (r *UserRepo) candidates(ctx context.Context, db repository.Querier, match func(u domain.User) bool) ([]domain.Candidate, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	now := time.Now()
+	absent := make(map[string]bool)
+	for _, a := range d.absences {
+		if !a.StartsAt.After(now) && a.EndsAt.After(now) {
+			absent[a.UserID] = true
+		}
+	}
+	openReviews := d.openReviews()
+
+	var candidates []domain.Candidate
+	for _, u := range d.users {
+		if !u.IsActive || absent[u.ID] || !match(u) {
+			continue
+		}
+		candidates = append(candidates, domain.Candidate{User: copyUser(u), OpenReviews: openReviews[u.ID]})
+	}
+	slices.SortFunc(candidates, func(a, b domain.Candidate) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	logging.FromContext(ctx).Debug("Loaded reviewer candidates", zap.Int("count", len(candidates)))
+	return candidates, nil
+}
+
+func (r *UserRepo) SetTags(ctx context.Context, db repository.Querier, userID string, tags []string) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	u, ok := d.users[userID]
+	if !ok {
+		return fmt.Errorf("failed to set user tags: user %s: %w", userID, domain.ErrNotFound)
+	}
+	u.Tags = slices.Compact(slices.Sorted(slices.Values(tags)))
+	d.users[userID] = u
+	return nil
+}
+
+func (r *UserRepo) AddAbsence(ctx context.Context, db repository.Querier, absence domain.Absence) (*domain.Absence, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if _, ok := d.users[absence.UserID]; !ok {
+		return nil, fmt.Errorf("failed to insert absence: user %s: %w", absence.UserID, domain.ErrNotFound)
+	}
+	if !absence.EndsAt.After(absence.StartsAt) {
+		return nil, fmt.Errorf("failed to insert absence: %w", domain.ErrConflict)
+	}
+
+	d.absenceSeq++
+	absence.ID = d.absenceSeq
+	d.absences = append(d.absences, absence)
+	return &absence, nil
+}
+
+// ListAbsences возвращает текущие и будущие отсутствия пользователя.
+func (r *UserRepo) ListAbsences(ctx context.Context, db repository.Querier, userID string) ([]domain.Absence, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	now := time.Now()
+	absences := []domain.Absence{}
+	for _, a := range d.absences {
+		if a.UserID == userID && a.EndsAt.After(now) {
+			absences = append(absences, a)
+		}
+	}
+	slices.SortStableFunc(absences, func(a, b domain.Absence) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+	return absences, nil
+}
+
+func (r *UserRepo) DeleteAbsence(ctx context.Context, db repository.Querier, userID string, absenceID int64) (bool, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	i := slices.IndexFunc(d.absences, func(a domain.Absence) bool {
+		return a.ID == absenceID && a.UserID == userID
+	})
+	if i < 0 {
+		return false, nil
+	}
+	d.absences = slices.Delete(d.absences, i, i+1)
+	return true, nil
+}
+
+// SetExternalLogin привязывает логин к пользователю, снимая прежние привязки
+// этого логина и этого пользователя у того же провайдера.
+func (r *UserRepo) SetExternalLogin(ctx context.Context, db repository.Querier, account domain.ExternalAccount) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if _, ok := d.users[account.UserID]; !ok {
+		return fmt.Errorf("failed to insert external account: user %s: %w", account.UserID, domain.ErrNotFound)
+	}
+	for key, userID := range d.externals {
+		if key.provider == account.Provider && userID == account.UserID {
+			delete(d.externals, key)
+		}
+	}
+	d.externals[externalKey{provider: account.Provider, login: account.Login}] = account.UserID
+	return nil
+}
+
+func (r *UserRepo) GetByExternalLogin(ctx context.Context, db repository.Querier, provider domain.Provider, login string) (*domain.User, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	userID, ok := d.externals[externalKey{provider: provider, login: login}]
+	if !ok {
+		return nil, nil
+	}
+	u, ok := d.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	u = copyUser(u)
+	return &u, nil
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"time"
+)
+
+type WebhookRepo struct{}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{}
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, db repository.Querier, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	d.subscriptionSeq++
+	sub.ID = d.subscriptionSeq
+	sub.IsActive = true
+	sub.CreatedAt = time.Now()
+	sub.Events = append([]domain.EventType{}, sub.Events...)
+	d.subscriptions = append(d.subscriptions, sub)
+
+	sub.Events = slices.Clone(sub.Events)
+	return &sub, nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context, db repository.Querier) ([]domain.WebhookSubscription, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	subs := make([]domain.WebhookSubscription, len(d.subscriptions))
+	for i, sub := range d.subscriptions {
+		sub.Events = append([]domain.EventType{}, sub.Events...)
+		subs[i] = sub
+	}
+	return subs, nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, db repository.Querier, id int64) (bool, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	i := slices.IndexFunc(d.subscriptions, func(sub domain.WebhookSubscription) bool {
+		return sub.ID == id
+	})
+	if i < 0 {
+		return false, nil
+	}
+	d.subscriptions = slices.Delete(d.subscriptions, i, i+1)
+	d.deliveries = slices.DeleteFunc(d.deliveries, func(dl domain.WebhookDelivery) bool {
+		return dl.SubscriptionID == id
+	})
+	return true, nil
+}
+
+func (r *WebhookRepo) AddEvent(ctx context.Context, db repository.Querier, eventType domain.EventType, payload []byte) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	d.eventSeq++
+	d.events = append(d.events, outboxEvent{
+		id:        d.eventSeq,
+		eventType: eventType,
+		payload:   slices.Clone(payload),
+		createdAt: time.Now(),
+	})
+	return nil
+}
+
+// FanOutEvents создаёт доставки неразосланных событий для всех подходящих
+// активных подписок и помечает события разосланными.
+func (r *WebhookRepo) FanOutEvents(ctx context.Context, db repository.Querier, limit int) (int, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	now := time.Now()
+	count := 0
+	for i := range d.events {
+		if count == limit {
+			break
+		}
+		e := &d.events[i]
+		if e.dispatchedAt != nil {
+			continue
+		}
+
+		for _, sub := range d.subscriptions {
+			if !sub.IsActive || (len(sub.Events) > 0 && !slices.Contains(sub.Events, e.eventType)) {
+				continue
+			}
+			d.deliverySeq++
+			d.deliveries = append(d.deliveries, domain.WebhookDelivery{
+				ID:             d.deliverySeq,
+				SubscriptionID: sub.ID,
+				EventID:        e.id,
+				EventType:      e.eventType,
+				Status:         domain.DeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+			})
+		}
+		e.dispatchedAt = &now
+		count++
+	}
+	return count, nil
+}
+
+// ClaimDeliveries берёт в работу доставки, время попытки которых наступило.
+// Попытка засчитывается сразу, а следующая откладывается на lease.
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, db repository.Querier, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	now := time.Now()
+	var due []int
+	for i, dl := range d.deliveries {
+		if dl.Status == domain.DeliveryPending && !dl.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b int) int {
+		return d.deliveries[a].NextAttemptAt.Compare(d.deliveries[b].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	deliveries := []domain.WebhookDelivery{}
+	for _, i := range due {
+		dl := &d.deliveries[i]
+		dl.Attempts++
+		dl.NextAttemptAt = now.Add(lease)
+
+		claimed := *dl
+		for _, sub := range d.subscriptions {
+			if sub.ID == dl.SubscriptionID {
+				claimed.URL, claimed.Secret = sub.URL, sub.Secret
+			}
+		}
+		for _, e := range d.events {
+			if e.id == dl.EventID {
+				claimed.Payload = slices.Clone(e.payload)
+			}
+		}
+		deliveries = append(deliveries, claimed)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) CompleteDelivery(ctx context.Context, db repository.Querier, delivery domain.WebhookDelivery) error {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	for i := range d.deliveries {
+		dl := &d.deliveries[i]
+		if dl.ID != delivery.ID {
+			continue
+		}
+		dl.Status = delivery.Status
+		dl.NextAttemptAt = delivery.NextAttemptAt
+		dl.ResponseStatus = delivery.ResponseStatus
+		dl.LastError = delivery.LastError
+		dl.DeliveredAt = delivery.DeliveredAt
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки; subscriptionID = 0 — по всем подпискам.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, db repository.Querier, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	d, done, err := use(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	deliveries := []domain.WebhookDelivery{}
+	for i := len(d.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		dl := d.deliveries[i]
+		if subscriptionID == 0 || dl.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, dl)
+		}
+	}
+	return deliveries, nil
+}
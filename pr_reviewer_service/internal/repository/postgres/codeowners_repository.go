@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+)
+
+type CodeOwnersRepo struct{}
+
+func NewCodeOwnersRepo() *CodeOwnersRepo {
+	return &CodeOwnersRepo{}
+}
+
+func (r *CodeOwnersRepo) ReplaceRules(ctx context.Context, db repository.Querier, rules []domain.OwnershipRule) error {
+	if _, err := execContext(ctx, db, "DELETE FROM code_owner_rules"); err != nil {
+		return fmt.Errorf("failed to clear code owner rules: %w", err)
+	}
+
+	queryRule := "INSERT INTO code_owner_rules (position, pattern) VALUES ($1, $2) RETURNING id"
+	queryOwner := `
+		INSERT INTO code_owner_rule_owners (rule_id, owner_type, owner_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	for i, rule := range rules {
+		var ruleID int
+		if err := queryRowContext(ctx, db, queryRule, i, rule.Pattern).Scan(&ruleID); err != nil {
+			return fmt.Errorf("failed to insert code owner rule %q: %w", rule.Pattern, err)
+		}
+
+		for _, owner := range rule.Owners {
+			if _, err := execContext(ctx, db, queryOwner, ruleID, owner.Type, owner.ID); err != nil {
+				return fmt.Errorf("failed to insert owner %s of rule %q: %w", owner.ID, rule.Pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *CodeOwnersRepo) ListRules(ctx context.Context, db repository.Querier) ([]domain.OwnershipRule, error) {
+	query := `
+		SELECT r.id, r.pattern, o.owner_type, o.owner_id
+		FROM code_owner_rules r
+		LEFT JOIN code_owner_rule_owners o ON o.rule_id = r.id
+		ORDER BY r.position, o.owner_type, o.owner_id
+	`
+	rows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list code owner rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []domain.OwnershipRule{}
+	lastID := -1
+	for rows.Next() {
+		var id int
+		var pattern string
+		var ownerType, ownerID *string
+		if err := rows.Scan(&id, &pattern, &ownerType, &ownerID); err != nil {
+			return nil, err
+		}
+
+		if id != lastID {
+			rules = append(rules, domain.OwnershipRule{Pattern: pattern, Owners: []domain.Owner{}})
+			lastID = id
+		}
+		if ownerType != nil && ownerID != nil {
+			rule := &rules[len(rules)-1]
+			rule.Owners = append(rule.Owners, domain.Owner{Type: domain.OwnerType(*ownerType), ID: *ownerID})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
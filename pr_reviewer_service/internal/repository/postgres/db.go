@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pr-reviewer/internal/repository"
+)
+
+// DB — хранилище на PostgreSQL.
+type DB struct {
+	*sql.DB
+}
+
+func NewDB(db *sql.DB) *DB {
+	return &DB{DB: db}
+}
+
+const backend = "postgres"
+
+func (db *DB) Backend() string {
+	return backend
+}
+
+func (db *DB) BeginTx(ctx context.Context) (repository.Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+// Tx — транзакция PostgreSQL. Rollback после Commit ничего не делает.
+type Tx struct {
+	*sql.Tx
+}
+
+func (tx *Tx) Backend() string {
+	return backend
+}
+
+func (tx *Tx) Rollback() error {
+	if err := tx.Tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
+
+// sqlQuerier — общие методы *sql.DB и *sql.Tx.
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn возвращает SQL-подключение, стоящее за db.
+func conn(db repository.Querier) (sqlQuerier, error) {
+	switch db := db.(type) {
+	case *DB:
+		return db.DB, nil
+	case *Tx:
+		return db.Tx, nil
+	}
+	return nil, fmt.Errorf("%w: %s repository got %T", repository.ErrUnsupportedQuerier, backend, db)
+}
+
+func execContext(ctx context.Context, db repository.Querier, query string, args ...any) (sql.Result, error) {
+	q, err := conn(db)
+	if err != nil {
+		return nil, err
+	}
+	return q.ExecContext(ctx, query, args...)
+}
+
+func queryContext(ctx context.Context, db repository.Querier, query string, args ...any) (*sql.Rows, error) {
+	q, err := conn(db)
+	if err != nil {
+		return nil, err
+	}
+	return q.QueryContext(ctx, query, args...)
+}
+
+// row — строка результата; ошибку подключения возвращает её Scan.
+type row interface {
+	Scan(dest ...any) error
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+func queryRowContext(ctx context.Context, db repository.Querier, query string, args ...any) row {
+	q, err := conn(db)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return q.QueryRowContext(ctx, query, args...)
+}
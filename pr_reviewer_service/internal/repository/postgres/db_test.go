@@ -0,0 +1,103 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"pr-reviewer/internal/repository/postgres"
+	"pr-reviewer/internal/repository/repotest"
+	"pr-reviewer/internal/schema"
+	"strings"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+)
+
+// openTestDB подключается к базе из TEST_POSTGRES_DSN и применяет миграции.
+// Без переменной тесты PostgreSQL пропускаются.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	if err := schema.AutoMigrate(context.Background(), dsn, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// truncate очищает все таблицы схемы, кроме версии миграций.
+func truncate(t *testing.T, db *sql.DB) {
+	t.Helper()
+	ctx := context.Background()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT quote_ident(tablename) FROM pg_tables
+		WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) == 0 {
+		return
+	}
+	if _, err := db.ExecContext(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContract(t *testing.T) {
+	db := openTestDB(t)
+
+	repotest.Run(t, func(t *testing.T) repotest.Storage {
+		truncate(t, db)
+		return repotest.Storage{
+			DB:     postgres.NewDB(db),
+			Teams:  postgres.NewTeamRepo(),
+			Users:  postgres.NewUserRepo(),
+			PRs:    postgres.NewPRRepo(),
+			Hooks:  postgres.NewWebhookRepo(),
+			Tokens: postgres.NewTokenRepo(),
+			Owners: postgres.NewCodeOwnersRepo(),
+		}
+	})
+}
+
+func TestSchemaIsUpToDate(t *testing.T) {
+	openTestDB(t)
+
+	mg, err := schema.New(os.Getenv("TEST_POSTGRES_DSN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mg.Close()
+
+	status, err := mg.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.UpToDate() || status.Version != schema.LatestVersion() {
+		t.Errorf("schema status = %+v, want version %d", status, schema.LatestVersion())
+	}
+}
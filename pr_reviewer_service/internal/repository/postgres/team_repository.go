@@ -17,7 +17,7 @@ func NewTeamRepo() *TeamRepo {
 func (r *TeamRepo) Create(ctx context.Context, db repository.Querier, team domain.Team) error {
 	query := "INSERT INTO teams (name) VALUES ($1)"
 
-	_, err := db.ExecContext(ctx, query, team.Name)
+	_, err := execContext(ctx, db, query, team.Name)
 	if err != nil {
 		return fmt.Errorf("failed to insert team: %w", err)
 	}
@@ -28,7 +28,7 @@ func (r *TeamRepo) GetByName(ctx context.Context, db repository.Querier, name st
 	queryTeam := "SELECT name FROM teams WHERE name = $1"
 
 	var team domain.Team
-	err := db.QueryRowContext(ctx, queryTeam, name).Scan(&team.Name)
+	err := queryRowContext(ctx, db, queryTeam, name).Scan(&team.Name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -37,11 +37,11 @@ func (r *TeamRepo) GetByName(ctx context.Context, db repository.Querier, name st
 	}
 
 	queryMembers := `
-		SELECT id, username, is_active, team_name
-		FROM users
-		WHERE team_name = $1
+		SELECT u.id, u.username, u.is_active, u.team_name, ` + userTagsColumn + `
+		FROM users u
+		WHERE u.team_name = $1
 	`
-	rows, err := db.QueryContext(ctx, queryMembers, name)
+	rows, err := queryContext(ctx, db, queryMembers, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team members: %w", err)
 	}
@@ -49,11 +49,129 @@ func (r *TeamRepo) GetByName(ctx context.Context, db repository.Querier, name st
 
 	for rows.Next() {
 		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName); err != nil {
+		var tags string
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &tags); err != nil {
 			return nil, err
 		}
+		u.Tags = splitTags(tags)
 		team.Members = append(team.Members, u)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return &team, nil
 }
+
+func (r *TeamRepo) Exists(ctx context.Context, db repository.Querier, name string) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)"
+	err := queryRowContext(ctx, db, query, name).Scan(&exists)
+	return exists, err
+}
+
+func (r *TeamRepo) GetSettings(ctx context.Context, db repository.Querier, name string) (*domain.TeamSettings, error) {
+	query := `
+		SELECT team_name, reviewer_count, min_reviewers, strategy, reassign_on_deactivate,
+			required_approvals, block_on_changes_requested, sla_hours, sla_action, owner_fallback
+		FROM team_settings
+		WHERE team_name = $1
+	`
+
+	var settings domain.TeamSettings
+	var strategy, slaAction sql.NullString
+	var slaHours sql.NullInt64
+	err := queryRowContext(ctx, db, query, name).Scan(
+		&settings.TeamName, &settings.ReviewerCount, &settings.MinReviewers, &strategy,
+		&settings.ReassignOnDeactivate, &settings.RequiredApprovals, &settings.BlockOnChangesRequested,
+		&slaHours, &slaAction, &settings.OwnerFallback,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+	settings.Strategy = strategy.String
+	if slaHours.Valid {
+		hours := int(slaHours.Int64)
+		settings.SLAHours = &hours
+	}
+	settings.SLAAction = domain.SLAAction(slaAction.String)
+
+	return &settings, nil
+}
+
+func (r *TeamRepo) UpsertSettings(ctx context.Context, db repository.Querier, settings domain.TeamSettings) error {
+	query := `
+		INSERT INTO team_settings (
+			team_name, reviewer_count, min_reviewers, strategy, reassign_on_deactivate,
+			required_approvals, block_on_changes_requested, sla_hours, sla_action, owner_fallback
+		)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, NULLIF($9, ''), $10)
+		ON CONFLICT (team_name) DO UPDATE
+		SET reviewer_count = EXCLUDED.reviewer_count,
+			min_reviewers = EXCLUDED.min_reviewers,
+			strategy = EXCLUDED.strategy,
+			reassign_on_deactivate = EXCLUDED.reassign_on_deactivate,
+			required_approvals = EXCLUDED.required_approvals,
+			block_on_changes_requested = EXCLUDED.block_on_changes_requested,
+			sla_hours = EXCLUDED.sla_hours,
+			sla_action = EXCLUDED.sla_action,
+			owner_fallback = EXCLUDED.owner_fallback
+	`
+
+	_, err := execContext(ctx, db, query,
+		settings.TeamName, settings.ReviewerCount, settings.MinReviewers, settings.Strategy,
+		settings.ReassignOnDeactivate, settings.RequiredApprovals, settings.BlockOnChangesRequested,
+		settings.SLAHours, settings.SLAAction, settings.OwnerFallback,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert team settings: %w", err)
+	}
+	return nil
+}
+
+func (r *TeamRepo) GetFallbacks(ctx context.Context, db repository.Querier, name string) ([]string, error) {
+	query := `
+		SELECT fallback_team_name
+		FROM team_fallbacks
+		WHERE team_name = $1
+		ORDER BY priority
+	`
+	rows, err := queryContext(ctx, db, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fallback teams: %w", err)
+	}
+	defer rows.Close()
+
+	fallbacks := []string{}
+	for rows.Next() {
+		var team string
+		if err := rows.Scan(&team); err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return fallbacks, nil
+}
+
+func (r *TeamRepo) SetFallbacks(ctx context.Context, db repository.Querier, name string, fallbackTeams []string) error {
+	_, err := execContext(ctx, db, "DELETE FROM team_fallbacks WHERE team_name = $1", name)
+	if err != nil {
+		return fmt.Errorf("failed to clear fallback teams: %w", err)
+	}
+
+	query := "INSERT INTO team_fallbacks (team_name, fallback_team_name, priority) VALUES ($1, $2, $3)"
+	for i, fallback := range fallbackTeams {
+		if _, err := execContext(ctx, db, query, name, fallback, i); err != nil {
+			return fmt.Errorf("failed to insert fallback team %s: %w", fallback, err)
+		}
+	}
+	return nil
+}
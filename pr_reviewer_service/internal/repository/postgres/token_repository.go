@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+)
+
+type TokenRepo struct{}
+
+func NewTokenRepo() *TokenRepo {
+	return &TokenRepo{}
+}
+
+const tokenColumns = "id, name, role, team_name, user_id, created_at, expires_at, revoked_at"
+
+func (r *TokenRepo) Create(ctx context.Context, db repository.Querier, token domain.APIToken, hash string) (*domain.APIToken, error) {
+	query := `
+		INSERT INTO api_tokens (name, token_hash, role, team_name, user_id, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+		RETURNING id, created_at
+	`
+	err := queryRowContext(ctx, db, query, token.Name, hash, token.Role, token.TeamName, token.UserID, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert API token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *TokenRepo) GetByHash(ctx context.Context, db repository.Querier, hash string) (*domain.APIToken, error) {
+	query := "SELECT " + tokenColumns + " FROM api_tokens WHERE token_hash = $1"
+	token, err := scanToken(queryRowContext(ctx, db, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	return token, nil
+}
+
+func (r *TokenRepo) List(ctx context.Context, db repository.Querier) ([]domain.APIToken, error) {
+	query := "SELECT " + tokenColumns + " FROM api_tokens ORDER BY id"
+	rows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []domain.APIToken{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke отзывает токен; false — токена нет или он уже отозван.
+func (r *TokenRepo) Revoke(ctx context.Context, db repository.Querier, id int64) (bool, error) {
+	res, err := execContext(ctx, db, "UPDATE api_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func scanToken(row interface{ Scan(dest ...any) error }) (*domain.APIToken, error) {
+	var t domain.APIToken
+	var teamName, userID sql.NullString
+	var expiresAt, revokedAt sql.NullTime
+	if err := row.Scan(&t.ID, &t.Name, &t.Role, &teamName, &userID, &t.CreatedAt, &expiresAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	t.TeamName = teamName.String
+	t.UserID = userID.String
+	if expiresAt.Valid {
+		t.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return &t, nil
+}
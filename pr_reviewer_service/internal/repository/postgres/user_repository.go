@@ -5,10 +5,23 @@ import (
 	"database/sql"
 	"fmt"
 	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
 	"pr-reviewer/internal/repository"
 	"strings"
+
+	"go.uber.org/zap"
 )
 
+// userTagsColumn собирает теги пользователя u в одну строку через запятую.
+const userTagsColumn = `COALESCE((SELECT string_agg(t.tag, ',' ORDER BY t.tag) FROM user_tags t WHERE t.user_id = u.id), '')`
+
+func splitTags(raw string) []string {
+	if raw == "" {
+		return []string{}
+	}
+	return strings.Split(raw, ",")
+}
+
 type UserRepo struct{}
 
 func NewUserRepo() *UserRepo {
@@ -38,70 +51,239 @@ func (r *UserRepo) Upsert(ctx context.Context, db repository.Querier, users []do
 			team_name = EXCLUDED.team_name
 	`, strings.Join(valueStrings, ","))
 
-	_, err := db.ExecContext(ctx, query, valueArgs...)
+	_, err := execContext(ctx, db, query, valueArgs...)
 	return err
 }
 
 func (r *UserRepo) SetIsActive(ctx context.Context, db repository.Querier, userID string, isActive bool) (*domain.User, error) {
 	query := `
-		UPDATE users
+		UPDATE users u
 		SET is_active = $2
-		WHERE id = $1
-		RETURNING id, username, is_active, team_name
-	`
+		WHERE u.id = $1
+		RETURNING u.id, u.username, u.is_active, u.team_name, ` + userTagsColumn
 
 	var u domain.User
-	err := db.QueryRowContext(ctx, query, userID, isActive).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName)
+	var tags string
+	err := queryRowContext(ctx, db, query, userID, isActive).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &tags)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to update user active status: %w", err)
 	}
+	u.Tags = splitTags(tags)
 
 	return &u, nil
 }
 
 func (r *UserRepo) GetByID(ctx context.Context, db repository.Querier, userID string) (*domain.User, error) {
-	query := "SELECT id, username, is_active, team_name FROM users WHERE id = $1"
+	query := "SELECT u.id, u.username, u.is_active, u.team_name, " + userTagsColumn + " FROM users u WHERE u.id = $1"
 	var u domain.User
-	err := db.QueryRowContext(ctx, query, userID).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName)
+	var tags string
+	err := queryRowContext(ctx, db, query, userID).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &tags)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	u.Tags = splitTags(tags)
 	return &u, nil
 }
 
-func (r *UserRepo) GetActiveCandidates(ctx context.Context, db repository.Querier, teamName string, excludeUserIDs []string) ([]domain.User, error) {
-	query := "SELECT id, username, is_active, team_name FROM users WHERE team_name = $1 AND is_active = true"
+const candidatesQuery = `
+	SELECT u.id, u.username, u.is_active, u.team_name, ` + userTagsColumn + `, COUNT(pr.id)
+	FROM users u
+	LEFT JOIN pull_requests_reviewers prr ON prr.reviewer_id = u.id
+	LEFT JOIN pull_requests pr ON pr.id = prr.pull_request_id AND pr.status = 'OPEN'
+	WHERE u.is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM user_absences a
+			WHERE a.user_id = u.id AND a.starts_at <= NOW() AND a.ends_at > NOW()
+		)`
+
+const candidatesGroupBy = " GROUP BY u.id, u.username, u.is_active, u.team_name"
+
+func (r *UserRepo) GetActiveCandidates(ctx context.Context, db repository.Querier, teamName string, excludeUserIDs []string) ([]domain.Candidate, error) {
+	query := candidatesQuery + " AND u.team_name = $1"
 	args := []any{teamName}
 
 	if len(excludeUserIDs) > 0 {
-		placeholders := make([]string, len(excludeUserIDs))
-		for i, id := range excludeUserIDs {
-			// +2, так как $1 занят teamName
-			placeholders[i] = fmt.Sprintf("$%d", i+2)
+		// +2, так как $1 занят teamName
+		query += fmt.Sprintf(" AND u.id NOT IN (%s)", placeholders(2, len(excludeUserIDs)))
+		for _, id := range excludeUserIDs {
 			args = append(args, id)
 		}
-		query += fmt.Sprintf(" AND id NOT IN (%s)", strings.Join(placeholders, ", "))
 	}
+	query += candidatesGroupBy
+
+	return r.queryCandidates(ctx, db, query, args)
+}
+
+func (r *UserRepo) GetActiveCandidatesByIDs(ctx context.Context, db repository.Querier, userIDs []string, excludeUserIDs []string) ([]domain.Candidate, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	query := candidatesQuery + fmt.Sprintf(" AND u.id IN (%s)", placeholders(1, len(userIDs)))
+	args := make([]any, 0, len(userIDs)+len(excludeUserIDs))
+	for _, id := range userIDs {
+		args = append(args, id)
+	}
+
+	if len(excludeUserIDs) > 0 {
+		query += fmt.Sprintf(" AND u.id NOT IN (%s)", placeholders(len(userIDs)+1, len(excludeUserIDs)))
+		for _, id := range excludeUserIDs {
+			args = append(args, id)
+		}
+	}
+	query += candidatesGroupBy
+
+	return r.queryCandidates(ctx, db, query, args)
+}
 
-	rows, err := db.QueryContext(ctx, query, args...)
+func (r *UserRepo) queryCandidates(ctx context.Context, db repository.Querier, query string, args []any) ([]domain.Candidate, error) {
+	rows, err := queryContext(ctx, db, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []domain.User
+	var candidates []domain.Candidate
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName); err != nil {
+		var c domain.Candidate
+		var tags string
+		if err := rows.Scan(&c.ID, &c.Username, &c.IsActive, &c.TeamName, &tags, &c.OpenReviews); err != nil {
 			return nil, err
 		}
-		users = append(users, u)
+		c.Tags = splitTags(tags)
+		candidates = append(candidates, c)
 	}
-	return users, nil
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("Loaded reviewer candidates", zap.Int("count", len(candidates)))
+	return candidates, nil
+}
+
+func (r *UserRepo) SetTags(ctx context.Context, db repository.Querier, userID string, tags []string) error {
+	if _, err := execContext(ctx, db, "DELETE FROM user_tags WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to clear user tags: %w", err)
+	}
+
+	query := "INSERT INTO user_tags (user_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+	for _, tag := range tags {
+		if _, err := execContext(ctx, db, query, userID, tag); err != nil {
+			return fmt.Errorf("failed to insert user tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func (r *UserRepo) AddAbsence(ctx context.Context, db repository.Querier, absence domain.Absence) (*domain.Absence, error) {
+	query := `
+		INSERT INTO user_absences (user_id, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	err := queryRowContext(ctx, db, query, absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason).Scan(&absence.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert absence: %w", err)
+	}
+	return &absence, nil
+}
+
+// ListAbsences возвращает текущие и будущие отсутствия пользователя.
+func (r *UserRepo) ListAbsences(ctx context.Context, db repository.Querier, userID string) ([]domain.Absence, error) {
+	query := `
+		SELECT id, user_id, starts_at, ends_at, reason
+		FROM user_absences
+		WHERE user_id = $1 AND ends_at > NOW()
+		ORDER BY starts_at
+	`
+	rows, err := queryContext(ctx, db, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list absences: %w", err)
+	}
+	defer rows.Close()
+
+	absences := []domain.Absence{}
+	for rows.Next() {
+		var a domain.Absence
+		if err := rows.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason); err != nil {
+			return nil, err
+		}
+		absences = append(absences, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return absences, nil
+}
+
+func (r *UserRepo) DeleteAbsence(ctx context.Context, db repository.Querier, userID string, absenceID int64) (bool, error) {
+	res, err := execContext(ctx, db, "DELETE FROM user_absences WHERE id = $1 AND user_id = $2", absenceID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete absence: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// placeholders возвращает "$start, $start+1, ..." для n параметров.
+func placeholders(start, n int) string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(result, ", ")
+}
+
+// SetExternalLogin привязывает логин к пользователю, снимая прежние привязки
+// этого логина и этого пользователя у того же провайдера.
+func (r *UserRepo) SetExternalLogin(ctx context.Context, db repository.Querier, account domain.ExternalAccount) error {
+	_, err := execContext(ctx, db,
+		"DELETE FROM user_external_accounts WHERE provider = $1 AND (login = $2 OR user_id = $3)",
+		account.Provider, account.Login, account.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear external account: %w", err)
+	}
+
+	_, err = execContext(ctx, db,
+		"INSERT INTO user_external_accounts (provider, login, user_id) VALUES ($1, $2, $3)",
+		account.Provider, account.Login, account.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert external account: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepo) GetByExternalLogin(ctx context.Context, db repository.Querier, provider domain.Provider, login string) (*domain.User, error) {
+	query := `
+		SELECT u.id, u.username, u.is_active, u.team_name, ` + userTagsColumn + `
+		FROM user_external_accounts ea
+		JOIN users u ON u.id = ea.user_id
+		WHERE ea.provider = $1 AND ea.login = $2
+	`
+	var u domain.User
+	var tags string
+	err := queryRowContext(ctx, db, query, provider, login).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &tags)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by external login: %w", err)
+	}
+	u.Tags = splitTags(tags)
+	return &u, nil
 }
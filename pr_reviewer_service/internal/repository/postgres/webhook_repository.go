@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"strings"
+	"time"
+)
+
+type WebhookRepo struct{}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{}
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, db repository.Querier, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, events)
+		VALUES ($1, $2, $3)
+		RETURNING id, is_active, created_at
+	`
+	err := queryRowContext(ctx, db, query, sub.URL, sub.Secret, joinEvents(sub.Events)).Scan(&sub.ID, &sub.IsActive, &sub.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context, db repository.Querier) ([]domain.WebhookSubscription, error) {
+	query := "SELECT id, url, secret, events, is_active, created_at FROM webhook_subscriptions ORDER BY id"
+	rows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []domain.WebhookSubscription{}
+	for rows.Next() {
+		var sub domain.WebhookSubscription
+		var events string
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &events, &sub.IsActive, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		sub.Events = splitEvents(events)
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, db repository.Querier, id int64) (bool, error) {
+	res, err := execContext(ctx, db, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *WebhookRepo) AddEvent(ctx context.Context, db repository.Querier, eventType domain.EventType, payload []byte) error {
+	query := "INSERT INTO outbox_events (event_type, payload) VALUES ($1, $2)"
+	if _, err := execContext(ctx, db, query, eventType, string(payload)); err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// FanOutEvents создаёт доставки неразосланных событий для всех подходящих
+// активных подписок и помечает события разосланными.
+func (r *WebhookRepo) FanOutEvents(ctx context.Context, db repository.Querier, limit int) (int, error) {
+	query := `
+		WITH events AS (
+			SELECT id, event_type
+			FROM outbox_events
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), deliveries AS (
+			INSERT INTO webhook_deliveries (subscription_id, event_id)
+			SELECT s.id, e.id
+			FROM events e
+			JOIN webhook_subscriptions s
+				ON s.is_active AND (s.events = '' OR e.event_type = ANY(string_to_array(s.events, ',')))
+			ON CONFLICT DO NOTHING
+		)
+		UPDATE outbox_events SET dispatched_at = NOW()
+		WHERE id IN (SELECT id FROM events)
+	`
+	res, err := execContext(ctx, db, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fan out outbox events: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// ClaimDeliveries берёт в работу доставки, время попытки которых наступило.
+// Попытка засчитывается сразу, а следующая откладывается на lease, чтобы
+// другой экземпляр сервиса не отправил то же событие параллельно.
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, db repository.Querier, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+			next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		FROM (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due, webhook_subscriptions s, outbox_events e
+		WHERE d.id = due.id AND s.id = d.subscription_id AND e.id = d.event_id
+		RETURNING d.id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts,
+			d.next_attempt_at, d.created_at, s.url, s.secret, e.payload
+	`
+	rows, err := queryContext(ctx, db, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var payload string
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.CreatedAt, &d.URL, &d.Secret, &payload)
+		if err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) CompleteDelivery(ctx context.Context, db repository.Querier, d domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			next_attempt_at = $3,
+			response_status = $4,
+			last_error = $5,
+			delivered_at = $6
+		WHERE id = $1
+	`
+	_, err := execContext(ctx, db, query, d.ID, d.Status, d.NextAttemptAt, d.ResponseStatus, d.LastError, d.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки; subscriptionID = 0 — по всем подпискам.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, db repository.Querier, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at,
+			d.response_status, d.last_error, d.created_at, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.event_id
+		WHERE $1 = 0 OR d.subscription_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2
+	`
+	rows, err := queryContext(ctx, db, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var responseStatus sql.NullInt64
+		var lastError sql.NullString
+		var deliveredAt sql.NullTime
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&responseStatus, &lastError, &d.CreatedAt, &deliveredAt)
+		if err != nil {
+			return nil, err
+		}
+		if responseStatus.Valid {
+			code := int(responseStatus.Int64)
+			d.ResponseStatus = &code
+		}
+		if lastError.Valid {
+			d.LastError = &lastError.String
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func joinEvents(events []domain.EventType) string {
+	parts := make([]string, len(events))
+	for i, e := range events {
+		parts[i] = string(e)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitEvents(raw string) []domain.EventType {
+	events := []domain.EventType{}
+	if raw == "" {
+		return events
+	}
+	for _, e := range strings.Split(raw, ",") {
+		events = append(events, domain.EventType(e))
+	}
+	return events
+}
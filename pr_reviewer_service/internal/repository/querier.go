@@ -2,11 +2,32 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 )
 
+// ErrUnsupportedQuerier — репозиторию передано подключение другого хранилища.
+var ErrUnsupportedQuerier = errors.New("querier belongs to a different storage")
+
+// Querier — подключение к хранилищу или открытая в нём транзакция, через
+// которые работают репозитории. Реализуют его только DB и Tx пакетов хранилищ;
+// репозиторий принимает лишь подключения своего хранилища и иначе возвращает
+// ErrUnsupportedQuerier.
 type Querier interface {
-	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
-	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
-	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	// Backend возвращает имя хранилища: postgres, sqlite или memory
+	Backend() string
+}
+
+// Tx — транзакция хранилища. Rollback после Commit ничего не делает,
+// поэтому его можно откладывать через defer сразу после BeginTx.
+type Tx interface {
+	Querier
+	Commit() error
+	Rollback() error
+}
+
+// DB — хранилище, в котором сервис читает данные вне транзакций
+// и открывает транзакции.
+type DB interface {
+	Querier
+	BeginTx(ctx context.Context) (Tx, error)
 }
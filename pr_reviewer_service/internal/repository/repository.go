@@ -3,18 +3,31 @@ package repository
 import (
 	"context"
 	"pr-reviewer/internal/domain"
+	"time"
 )
 
 type TeamRepository interface {
 	Create(ctx context.Context, db Querier, team domain.Team) error
 	GetByName(ctx context.Context, db Querier, name string) (*domain.Team, error)
+	Exists(ctx context.Context, db Querier, name string) (bool, error)
+	GetSettings(ctx context.Context, db Querier, name string) (*domain.TeamSettings, error)
+	UpsertSettings(ctx context.Context, db Querier, settings domain.TeamSettings) error
+	GetFallbacks(ctx context.Context, db Querier, name string) ([]string, error)
+	SetFallbacks(ctx context.Context, db Querier, name string, fallbackTeams []string) error
 }
 
 type UserRepository interface {
 	Upsert(ctx context.Context, db Querier, users []domain.User) error
 	SetIsActive(ctx context.Context, db Querier, userID string, isActive bool) (*domain.User, error)
 	GetByID(ctx context.Context, db Querier, userID string) (*domain.User, error)
-	GetActiveCandidates(ctx context.Context, db Querier, teamName string, excludeUserIDs []string) ([]domain.User, error)
+	GetActiveCandidates(ctx context.Context, db Querier, teamName string, excludeUserIDs []string) ([]domain.Candidate, error)
+	SetTags(ctx context.Context, db Querier, userID string, tags []string) error
+	AddAbsence(ctx context.Context, db Querier, absence domain.Absence) (*domain.Absence, error)
+	ListAbsences(ctx context.Context, db Querier, userID string) ([]domain.Absence, error)
+	DeleteAbsence(ctx context.Context, db Querier, userID string, absenceID int64) (bool, error)
+	GetActiveCandidatesByIDs(ctx context.Context, db Querier, userIDs []string, excludeUserIDs []string) ([]domain.Candidate, error)
+	SetExternalLogin(ctx context.Context, db Querier, account domain.ExternalAccount) error
+	GetByExternalLogin(ctx context.Context, db Querier, provider domain.Provider, login string) (*domain.User, error)
 }
 
 type PullRequestRepository interface {
@@ -23,5 +36,39 @@ type PullRequestRepository interface {
 	GetByID(ctx context.Context, db Querier, id string) (*domain.PullRequest, error)
 	SetStatus(ctx context.Context, db Querier, id string, status domain.PRStatus) error
 	ReplaceReviewer(ctx context.Context, db Querier, prID, oldReviewerID, newReviewerID string) error
+	AddReviewer(ctx context.Context, db Querier, prID, reviewerID string) error
+	RemoveReviewer(ctx context.Context, db Querier, prID, reviewerID string) error
 	GetByReviewerID(ctx context.Context, db Querier, reviewerID string) ([]domain.PullRequestShort, error)
+	AddReview(ctx context.Context, db Querier, review domain.Review) (*domain.Review, error)
+	GetReviews(ctx context.Context, db Querier, prID string) ([]domain.Review, error)
+	AddMergeOverride(ctx context.Context, db Querier, override domain.MergeOverride) error
+	GetAssignments(ctx context.Context, db Querier, prID string, defaultSLA domain.SLAPolicy) ([]domain.ReviewAssignment, error)
+	GetOverdueAssignments(ctx context.Context, db Querier, defaultSLA domain.SLAPolicy) ([]domain.ReviewAssignment, error)
+	MarkEscalated(ctx context.Context, db Querier, prID, reviewerID string) (bool, error)
+	RestartAssignments(ctx context.Context, db Querier, prID string) error
+	CountOpenReviews(ctx context.Context, db Querier) (map[string]int, error)
+}
+
+type CodeOwnersRepository interface {
+	ReplaceRules(ctx context.Context, db Querier, rules []domain.OwnershipRule) error
+	ListRules(ctx context.Context, db Querier) ([]domain.OwnershipRule, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, db Querier, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, db Querier) ([]domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, db Querier, id int64) (bool, error)
+
+	AddEvent(ctx context.Context, db Querier, eventType domain.EventType, payload []byte) error
+	FanOutEvents(ctx context.Context, db Querier, limit int) (int, error)
+	ClaimDeliveries(ctx context.Context, db Querier, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	CompleteDelivery(ctx context.Context, db Querier, delivery domain.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, db Querier, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error)
+}
+
+type TokenRepository interface {
+	Create(ctx context.Context, db Querier, token domain.APIToken, hash string) (*domain.APIToken, error)
+	GetByHash(ctx context.Context, db Querier, hash string) (*domain.APIToken, error)
+	List(ctx context.Context, db Querier) ([]domain.APIToken, error)
+	Revoke(ctx context.Context, db Querier, id int64) (bool, error)
 }
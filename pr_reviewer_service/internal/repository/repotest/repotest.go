@@ -0,0 +1,951 @@
+// Package repotest — общий набор проверок репозиториев. Его запускают тесты
+// каждого хранилища, чтобы PostgreSQL, SQLite и память вели себя одинаково.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Storage — хранилище и его репозитории.
+type Storage struct {
+	DB     repository.DB
+	Teams  repository.TeamRepository
+	Users  repository.UserRepository
+	PRs    repository.PullRequestRepository
+	Hooks  repository.WebhookRepository
+	Tokens repository.TokenRepository
+	Owners repository.CodeOwnersRepository
+}
+
+// Run запускает набор проверок. open вызывается в каждом подтесте
+// и должен возвращать пустое хранилище.
+func Run(t *testing.T, open func(t *testing.T) Storage) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s Storage)
+	}{
+		{"TeamMembersWithTags", testTeamMembersWithTags},
+		{"ActiveCandidates", testActiveCandidates},
+		{"ActiveCandidatesByIDs", testActiveCandidatesByIDs},
+		{"CandidateOpenReviews", testCandidateOpenReviews},
+		{"TeamSettings", testTeamSettings},
+		{"ExternalLogins", testExternalLogins},
+		{"CodeOwnerRules", testCodeOwnerRules},
+		{"PullRequestRoundTrip", testPullRequestRoundTrip},
+		{"ReviewerOpenPullRequests", testReviewerOpenPullRequests},
+		{"ReviewOrder", testReviewOrder},
+		{"EnumConstraints", testEnumConstraints},
+		{"Transactions", testTransactions},
+		{"ConcurrentTransactions", testConcurrentTransactions},
+		{"UnsupportedQuerier", testUnsupportedQuerier},
+		{"AssignmentSLA", testAssignmentSLA},
+		{"ClaimDeliveries", testClaimDeliveries},
+		{"Tokens", testTokens},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+// seedTeam создаёт команду backend: u1 и u2 активны, u3 — нет.
+func seedTeam(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := s.Teams.Create(ctx, s.DB, domain.Team{Name: "backend"}); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Users.Upsert(ctx, s.DB, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true, TeamName: "backend"},
+		{ID: "u2", Username: "bob", IsActive: true, TeamName: "backend"},
+		{ID: "u3", Username: "carol", IsActive: false, TeamName: "backend"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func seedPR(t *testing.T, s Storage, id string, reviewers ...string) {
+	t.Helper()
+	pr := domain.PullRequest{ID: id, Name: id, AuthorID: "u1", Status: domain.PRStatusOpen}
+	for _, r := range reviewers {
+		pr.Reviewers = append(pr.Reviewers, domain.User{ID: r})
+	}
+	if err := s.PRs.Create(context.Background(), s.DB, pr); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testTeamMembersWithTags(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+
+	if err := s.Users.SetTags(ctx, s.DB, "u1", []string{"sql", "go", "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	team, err := s.Teams.GetByName(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team == nil || len(team.Members) != 3 {
+		t.Fatalf("team = %+v, want backend with 3 members", team)
+	}
+	slices.SortFunc(team.Members, func(a, b domain.User) int { return strings.Compare(a.ID, b.ID) })
+	// Теги собираются в одну строку и разбираются обратно в порядке сортировки
+	if got := team.Members[0].Tags; !slices.Equal(got, []string{"go", "sql"}) {
+		t.Errorf("u1 tags = %v, want [go sql]", got)
+	}
+	if got := team.Members[1].Tags; len(got) != 0 {
+		t.Errorf("u2 tags = %v, want none", got)
+	}
+
+	// SetTags заменяет теги целиком
+	if err := s.Users.SetTags(ctx, s.DB, "u1", []string{"frontend"}); err != nil {
+		t.Fatal(err)
+	}
+	candidates, err := s.Users.GetActiveCandidates(ctx, s.DB, "backend", []string{"u2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "u1" || !slices.Equal(candidates[0].Tags, []string{"frontend"}) {
+		t.Errorf("candidates = %+v, want only u1 tagged frontend", candidates)
+	}
+}
+
+func candidateIDs(candidates []domain.Candidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// testActiveCandidates проверяет, что отсутствующие сейчас пользователи
+// не попадают в кандидаты, а OpenReviews считает их открытые ревью.
+func testActiveCandidates(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	err := s.Users.Upsert(ctx, s.DB, []domain.User{{ID: "u4", Username: "dave", IsActive: true, TeamName: "backend"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedPR(t, s, "pr-1", "u2", "u4")
+	seedPR(t, s, "pr-2", "u2")
+
+	now := time.Now()
+	absences := []domain.Absence{
+		{UserID: "u4", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Reason: "vacation"},
+		// Прошедшее и будущее отсутствия не мешают назначению
+		{UserID: "u1", StartsAt: now.Add(-48 * time.Hour), EndsAt: now.Add(-24 * time.Hour)},
+		{UserID: "u2", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour)},
+	}
+	for _, a := range absences {
+		if _, err := s.Users.AddAbsence(ctx, s.DB, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	candidates, err := s.Users.GetActiveCandidates(ctx, s.DB, "backend", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := candidateIDs(candidates); !slices.Equal(got, []string{"u1", "u2"}) {
+		t.Fatalf("candidates = %v, want [u1 u2]", got)
+	}
+	for _, c := range candidates {
+		want := map[string]int{"u1": 0, "u2": 2}[c.ID]
+		if c.OpenReviews != want {
+			t.Errorf("%s open reviews = %d, want %d", c.ID, c.OpenReviews, want)
+		}
+	}
+
+	candidates, err = s.Users.GetActiveCandidates(ctx, s.DB, "backend", []string{"u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := candidateIDs(candidates); !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("candidates excluding u1 = %v, want [u2]", got)
+	}
+
+	candidates, err = s.Users.GetActiveCandidates(ctx, s.DB, "frontend", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 0 {
+		t.Errorf("candidates of unknown team = %+v, want none", candidates)
+	}
+}
+
+// testCandidateOpenReviews проверяет, что нагрузка кандидата для least_loaded
+// считается только по открытым PR.
+func testCandidateOpenReviews(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	seedPR(t, s, "pr-open-1", "u2")
+	seedPR(t, s, "pr-open-2", "u2")
+	for id, status := range map[string]domain.PRStatus{
+		"pr-merged-1": domain.PRStatusMerged,
+		"pr-merged-2": domain.PRStatusMerged,
+		"pr-merged-3": domain.PRStatusMerged,
+		"pr-closed":   domain.PRStatusClosed,
+		"pr-draft":    domain.PRStatusDraft,
+	} {
+		seedPR(t, s, id, "u2")
+		if err := s.PRs.SetStatus(ctx, s.DB, id, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seedPR(t, s, "pr-open-3", "u1")
+
+	candidates, err := s.Users.GetActiveCandidates(ctx, s.DB, "backend", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loads := map[string]int{}
+	for _, c := range candidates {
+		loads[c.ID] = c.OpenReviews
+	}
+	if want := map[string]int{"u1": 1, "u2": 2}; !reflect.DeepEqual(loads, want) {
+		t.Errorf("open reviews = %v, want %v", loads, want)
+	}
+
+	candidates, err = s.Users.GetActiveCandidatesByIDs(ctx, s.DB, []string{"u1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].OpenReviews != 1 {
+		t.Errorf("candidates by id = %+v, want u1 with one open review", candidates)
+	}
+}
+
+func testActiveCandidatesByIDs(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	seedPR(t, s, "pr-1", "u2")
+
+	now := time.Now()
+	if _, err := s.Users.AddAbsence(ctx, s.DB, domain.Absence{UserID: "u1", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// u1 отсутствует, u3 неактивен, u404 не существует
+	candidates, err := s.Users.GetActiveCandidatesByIDs(ctx, s.DB, []string{"u1", "u2", "u3", "u404"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "u2" || candidates[0].TeamName != "backend" || candidates[0].OpenReviews != 1 {
+		t.Errorf("candidates = %+v, want u2 of backend with one open review", candidates)
+	}
+
+	candidates, err = s.Users.GetActiveCandidatesByIDs(ctx, s.DB, []string{"u2"}, []string{"u2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 0 {
+		t.Errorf("candidates with u2 excluded = %+v, want none", candidates)
+	}
+
+	candidates, err = s.Users.GetActiveCandidatesByIDs(ctx, s.DB, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 0 {
+		t.Errorf("candidates for no ids = %+v, want none", candidates)
+	}
+}
+
+func testTeamSettings(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	for _, name := range []string{"frontend", "platform"} {
+		if err := s.Teams.Create(ctx, s.DB, domain.Team{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	settings, err := s.Teams.GetSettings(ctx, s.DB, "backend")
+	if err != nil || settings != nil {
+		t.Fatalf("settings before upsert = %+v, %v, want nil, nil", settings, err)
+	}
+
+	want := domain.TeamSettings{
+		TeamName:                "backend",
+		ReviewerCount:           3,
+		MinReviewers:            1,
+		Strategy:                "least_loaded",
+		ReassignOnDeactivate:    true,
+		OwnerFallback:           true,
+		RequiredApprovals:       2,
+		BlockOnChangesRequested: true,
+		SLAHours:                intPtr(8),
+		SLAAction:               domain.SLAReassign,
+	}
+	if err := s.Teams.UpsertSettings(ctx, s.DB, want); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = s.Teams.GetSettings(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings == nil || !reflect.DeepEqual(*settings, want) {
+		t.Errorf("settings = %+v, want %+v", settings, want)
+	}
+
+	// Повторный upsert перезаписывает все поля; пустая стратегия и незаданный
+	// SLA сохраняются как NULL и читаются обратно незаданными
+	want = domain.TeamSettings{TeamName: "backend", ReviewerCount: 1}
+	if err := s.Teams.UpsertSettings(ctx, s.DB, want); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = s.Teams.GetSettings(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings == nil || !reflect.DeepEqual(*settings, want) {
+		t.Errorf("settings after overwrite = %+v, want %+v", settings, want)
+	}
+
+	// Явный ноль отличается от незаданного срока
+	want.SLAHours = intPtr(0)
+	if err := s.Teams.UpsertSettings(ctx, s.DB, want); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = s.Teams.GetSettings(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings == nil || settings.SLAHours == nil || *settings.SLAHours != 0 {
+		t.Errorf("settings with sla_hours 0 = %+v, want explicit 0", settings)
+	}
+
+	// Резервные команды хранятся отдельно в порядке приоритета
+	if err := s.Teams.SetFallbacks(ctx, s.DB, "backend", []string{"platform", "frontend"}); err != nil {
+		t.Fatal(err)
+	}
+	fallbacks, err := s.Teams.GetFallbacks(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(fallbacks, []string{"platform", "frontend"}) {
+		t.Errorf("fallbacks = %v, want [platform frontend]", fallbacks)
+	}
+
+	if err := s.Teams.SetFallbacks(ctx, s.DB, "backend", []string{"frontend"}); err != nil {
+		t.Fatal(err)
+	}
+	fallbacks, err = s.Teams.GetFallbacks(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(fallbacks, []string{"frontend"}) {
+		t.Errorf("fallbacks after replace = %v, want [frontend]", fallbacks)
+	}
+
+	if err := s.Teams.SetFallbacks(ctx, s.DB, "backend", nil); err != nil {
+		t.Fatal(err)
+	}
+	fallbacks, err = s.Teams.GetFallbacks(ctx, s.DB, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fallbacks) != 0 {
+		t.Errorf("fallbacks after clear = %v, want none", fallbacks)
+	}
+}
+
+func testExternalLogins(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+
+	link := func(provider domain.Provider, login, userID string) {
+		t.Helper()
+		if err := s.Users.SetExternalLogin(ctx, s.DB, domain.ExternalAccount{Provider: provider, Login: login, UserID: userID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lookup := func(provider domain.Provider, login string) string {
+		t.Helper()
+		user, err := s.Users.GetByExternalLogin(ctx, s.DB, provider, login)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user == nil {
+			return ""
+		}
+		return user.ID
+	}
+
+	link(domain.ProviderGitHub, "alice-gh", "u1")
+	link(domain.ProviderGitLab, "alice", "u1")
+	link(domain.ProviderGitLab, "bob", "u2")
+
+	if got := lookup(domain.ProviderGitHub, "alice-gh"); got != "u1" {
+		t.Errorf("github alice-gh = %q, want u1", got)
+	}
+	if got := lookup(domain.ProviderGitLab, "alice"); got != "u1" {
+		t.Errorf("gitlab alice = %q, want u1", got)
+	}
+	// Логины разных провайдеров не пересекаются
+	if got := lookup(domain.ProviderGitHub, "alice"); got != "" {
+		t.Errorf("github alice = %q, want no user", got)
+	}
+
+	// Логин переходит к новому пользователю, а у пользователя остаётся один логин на провайдера
+	link(domain.ProviderGitLab, "bob", "u1")
+	if got := lookup(domain.ProviderGitLab, "bob"); got != "u1" {
+		t.Errorf("gitlab bob after relink = %q, want u1", got)
+	}
+	if got := lookup(domain.ProviderGitLab, "alice"); got != "" {
+		t.Errorf("gitlab alice after relink = %q, want no user", got)
+	}
+	if got := lookup(domain.ProviderGitHub, "alice-gh"); got != "u1" {
+		t.Errorf("github alice-gh after gitlab relink = %q, want u1", got)
+	}
+
+	user, err := s.Users.GetByExternalLogin(ctx, s.DB, domain.ProviderGitHub, "alice-gh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice" || user.TeamName != "backend" || !user.IsActive {
+		t.Errorf("user = %+v, want active alice of backend", user)
+	}
+}
+
+func testCodeOwnerRules(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	rules, err := s.Owners.ListRules(ctx, s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules == nil || len(rules) != 0 {
+		t.Errorf("rules before import = %#v, want an empty list", rules)
+	}
+
+	err = s.Owners.ReplaceRules(ctx, s.DB, []domain.OwnershipRule{
+		{Pattern: "*", Owners: []domain.Owner{{Type: domain.OwnerUser, ID: "u2"}, {Type: domain.OwnerTeam, ID: "backend"}, {Type: domain.OwnerUser, ID: "u1"}}},
+		{Pattern: "/docs/", Owners: []domain.Owner{{Type: domain.OwnerUser, ID: "u3"}, {Type: domain.OwnerUser, ID: "u3"}}},
+		{Pattern: "/vendor/", Owners: []domain.Owner{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Правила сохраняют порядок файла, владельцы упорядочены и без повторов
+	want := []domain.OwnershipRule{
+		{Pattern: "*", Owners: []domain.Owner{{Type: domain.OwnerTeam, ID: "backend"}, {Type: domain.OwnerUser, ID: "u1"}, {Type: domain.OwnerUser, ID: "u2"}}},
+		{Pattern: "/docs/", Owners: []domain.Owner{{Type: domain.OwnerUser, ID: "u3"}}},
+		{Pattern: "/vendor/", Owners: []domain.Owner{}},
+	}
+	rules, err = s.Owners.ListRules(ctx, s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %+v, want %+v", rules, want)
+	}
+
+	// Импорт заменяет набор правил целиком
+	err = s.Owners.ReplaceRules(ctx, s.DB, []domain.OwnershipRule{{Pattern: "*.go", Owners: []domain.Owner{{Type: domain.OwnerUser, ID: "u1"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err = s.Owners.ListRules(ctx, s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Pattern != "*.go" {
+		t.Errorf("rules after replace = %+v, want only *.go", rules)
+	}
+}
+
+func testPullRequestRoundTrip(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+
+	err := s.PRs.Create(ctx, s.DB, domain.PullRequest{
+		ID:           "pr-1",
+		Name:         "Add search",
+		AuthorID:     "u1",
+		Status:       domain.PRStatusOpen,
+		Reviewers:    []domain.User{{ID: "u2"}},
+		Labels:       []string{"sql", "go", "go"},
+		ChangedFiles: []string{"internal/search.go", "docs/search.md"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := s.PRs.GetByID(ctx, s.DB, "pr-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr == nil || pr.Name != "Add search" || pr.AuthorID != "u1" || pr.Status != domain.PRStatusOpen || pr.MergedAt != nil {
+		t.Fatalf("pr = %+v, want open pr-1 by u1", pr)
+	}
+	if len(pr.Reviewers) != 1 || pr.Reviewers[0].ID != "u2" {
+		t.Errorf("reviewers = %+v, want u2", pr.Reviewers)
+	}
+	if !slices.Equal(pr.Labels, []string{"go", "sql"}) {
+		t.Errorf("labels = %v, want [go sql]", pr.Labels)
+	}
+	if !slices.Equal(pr.ChangedFiles, []string{"docs/search.md", "internal/search.go"}) {
+		t.Errorf("changed files = %v", pr.ChangedFiles)
+	}
+
+	counts, err := s.PRs.CountOpenReviews(ctx, s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["u2"] != 1 {
+		t.Errorf("open reviews = %v, want u2: 1", counts)
+	}
+
+	if err := s.PRs.SetStatus(ctx, s.DB, "pr-1", domain.PRStatusMerged); err != nil {
+		t.Fatal(err)
+	}
+	pr, err = s.PRs.GetByID(ctx, s.DB, "pr-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Status != domain.PRStatusMerged || pr.MergedAt == nil {
+		t.Errorf("pr = %+v, want merged with merged_at", pr)
+	}
+	counts, err = s.PRs.CountOpenReviews(ctx, s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["u2"] != 0 {
+		t.Errorf("open reviews after merge = %v, want none", counts)
+	}
+
+	missing, err := s.PRs.GetByID(ctx, s.DB, "pr-404")
+	if err != nil || missing != nil {
+		t.Errorf("GetByID(pr-404) = %+v, %v, want nil, nil", missing, err)
+	}
+}
+
+// testReviewerOpenPullRequests проверяет, что в списке ревью пользователя
+// остаются только открытые PR.
+func testReviewerOpenPullRequests(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	for _, id := range []string{"pr-open", "pr-closed", "pr-merged", "pr-draft"} {
+		seedPR(t, s, id, "u2")
+	}
+	for id, status := range map[string]domain.PRStatus{
+		"pr-closed": domain.PRStatusClosed,
+		"pr-merged": domain.PRStatusMerged,
+		"pr-draft":  domain.PRStatusDraft,
+	} {
+		if err := s.PRs.SetStatus(ctx, s.DB, id, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prs, err := s.PRs.GetByReviewerID(ctx, s.DB, "u2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 1 || prs[0].ID != "pr-open" || prs[0].Status != domain.PRStatusOpen {
+		t.Errorf("reviews of u2 = %+v, want only pr-open", prs)
+	}
+
+	prs, err = s.PRs.GetByReviewerID(ctx, s.DB, "u3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prs == nil || len(prs) != 0 {
+		t.Errorf("reviews of u3 = %#v, want an empty list", prs)
+	}
+}
+
+// testReviewOrder проверяет, что решения возвращаются в порядке добавления:
+// политика слияния берёт последнее решение каждого ревьюера.
+func testReviewOrder(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	seedPR(t, s, "pr-1", "u2", "u3")
+	seedPR(t, s, "pr-2", "u2")
+
+	reviews := []domain.Review{
+		{PullRequestID: "pr-1", ReviewerID: "u2", Decision: domain.ReviewChangesRequested},
+		{PullRequestID: "pr-2", ReviewerID: "u2", Decision: domain.ReviewApproved},
+		{PullRequestID: "pr-1", ReviewerID: "u3", Decision: domain.ReviewCommented},
+		{PullRequestID: "pr-1", ReviewerID: "u2", Decision: domain.ReviewApproved},
+	}
+	// Решения в одной транзакции получают одинаковое время в PostgreSQL
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	for _, rv := range reviews {
+		if _, err := s.PRs.AddReview(ctx, tx, rv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.PRs.GetReviews(ctx, s.DB, "pr-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"u2:CHANGES_REQUESTED", "u3:COMMENTED", "u2:APPROVED"}
+	var decisions []string
+	for _, rv := range got {
+		if rv.PullRequestID != "pr-1" || rv.CreatedAt.IsZero() {
+			t.Errorf("review = %+v, want pr-1 with created_at", rv)
+		}
+		decisions = append(decisions, rv.ReviewerID+":"+string(rv.Decision))
+	}
+	if !slices.Equal(decisions, want) {
+		t.Errorf("reviews = %v, want %v", decisions, want)
+	}
+
+	got, err = s.PRs.GetReviews(ctx, s.DB, "pr-404")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("reviews of pr-404 = %#v, want an empty list", got)
+	}
+}
+
+// testEnumConstraints проверяет, что хранилище отвергает значения вне
+// перечислений: в PostgreSQL это типы ENUM и CHECK, в SQLite — CHECK.
+func testEnumConstraints(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	seedPR(t, s, "pr-1", "u2")
+
+	err := s.PRs.Create(ctx, s.DB, domain.PullRequest{ID: "pr-2", Name: "pr-2", AuthorID: "u1", Status: "REVIEW"})
+	if err == nil {
+		t.Error("created PR with status REVIEW")
+	}
+	if err := s.PRs.SetStatus(ctx, s.DB, "pr-1", "REVIEW"); err == nil {
+		t.Error("set PR status to REVIEW")
+	}
+	_, err = s.PRs.AddReview(ctx, s.DB, domain.Review{PullRequestID: "pr-1", ReviewerID: "u2", Decision: "LGTM"})
+	if err == nil {
+		t.Error("added review with decision LGTM")
+	}
+	err = s.Teams.UpsertSettings(ctx, s.DB, domain.TeamSettings{TeamName: "backend", ReviewerCount: 2, SLAAction: "ignore"})
+	if err == nil {
+		t.Error("saved team settings with sla_action ignore")
+	}
+
+	pr, err := s.PRs.GetByID(ctx, s.DB, "pr-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Status != domain.PRStatusOpen {
+		t.Errorf("status = %s after rejected update, want OPEN", pr.Status)
+	}
+	reviews, err := s.PRs.GetReviews(ctx, s.DB, "pr-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 0 {
+		t.Errorf("reviews = %+v after rejected insert, want none", reviews)
+	}
+}
+
+func testTransactions(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Teams.Create(ctx, tx, domain.Team{Name: "rolled-back"}); err != nil {
+		t.Fatal(err)
+	}
+	// Транзакция видит свои изменения
+	if ok, err := s.Teams.Exists(ctx, tx, "rolled-back"); err != nil || !ok {
+		t.Errorf("Exists in tx = %v, %v, want true", ok, err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Teams.Exists(ctx, s.DB, "rolled-back"); err != nil || ok {
+		t.Errorf("Exists after rollback = %v, %v, want false", ok, err)
+	}
+
+	tx, err = s.DB.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Teams.Create(ctx, tx, domain.Team{Name: "committed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// Отложенный Rollback после Commit ничего не делает
+	if err := tx.Rollback(); err != nil {
+		t.Errorf("Rollback after Commit = %v, want nil", err)
+	}
+	if ok, err := s.Teams.Exists(ctx, s.DB, "committed"); err != nil || !ok {
+		t.Errorf("Exists after commit = %v, %v, want true", ok, err)
+	}
+}
+
+// testConcurrentTransactions запускает пишущие транзакции одновременно:
+// ни одна не должна упасть из-за блокировки хранилища.
+func testConcurrentTransactions(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- func() error {
+				tx, err := s.DB.BeginTx(ctx)
+				if err != nil {
+					return err
+				}
+				defer tx.Rollback()
+
+				// Чтение перед записью: в SQLite без единственного соединения
+				// такая транзакция получает SQLITE_BUSY при повышении блокировки
+				if _, err := s.PRs.CountOpenReviews(ctx, tx); err != nil {
+					return err
+				}
+				id := "pr-" + string(rune('a'+i))
+				pr := domain.PullRequest{ID: id, Name: id, AuthorID: "u1", Status: domain.PRStatusOpen, Reviewers: []domain.User{{ID: "u2"}}}
+				if err := s.PRs.Create(ctx, tx, pr); err != nil {
+					return err
+				}
+				return tx.Commit()
+			}()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	counts, err := s.PRs.CountOpenReviews(ctx, s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["u2"] != writers {
+		t.Errorf("open reviews = %v, want u2: %d", counts, writers)
+	}
+}
+
+type foreignQuerier struct{}
+
+func (foreignQuerier) Backend() string {
+	return "foreign"
+}
+
+func testUnsupportedQuerier(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	if _, err := s.Teams.Exists(ctx, foreignQuerier{}, "backend"); !errors.Is(err, repository.ErrUnsupportedQuerier) {
+		t.Errorf("Exists with foreign querier = %v, want ErrUnsupportedQuerier", err)
+	}
+	if err := s.Users.SetTags(ctx, foreignQuerier{}, "u1", []string{"go"}); !errors.Is(err, repository.ErrUnsupportedQuerier) {
+		t.Errorf("SetTags with foreign querier = %v, want ErrUnsupportedQuerier", err)
+	}
+}
+
+func testAssignmentSLA(t *testing.T, s Storage) {
+	ctx := context.Background()
+	seedTeam(t, s)
+	seedPR(t, s, "pr-1", "u2")
+	defaultSLA := domain.SLAPolicy{Hours: 24, Action: domain.SLAReassign}
+
+	// Команда без настроек получает SLA по умолчанию
+	assignments, err := s.PRs.GetAssignments(ctx, s.DB, "pr-1", defaultSLA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assignments) != 1 {
+		t.Fatalf("assignments = %+v, want one", assignments)
+	}
+	a := assignments[0]
+	if a.ReviewerID != "u2" || a.TeamName != "backend" || a.SLAHours != 24 || a.SLAAction != domain.SLAReassign || a.FirstActionAt != nil {
+		t.Errorf("assignment = %+v, want u2 with the default SLA", a)
+	}
+
+	// Незаданные в настройках поля SLA берутся из значений по умолчанию по отдельности
+	for _, tc := range []struct {
+		settings domain.TeamSettings
+		want     domain.SLAPolicy
+	}{
+		{domain.TeamSettings{TeamName: "backend", ReviewerCount: 2}, defaultSLA},
+		{domain.TeamSettings{TeamName: "backend", ReviewerCount: 2, SLAHours: intPtr(0)}, domain.SLAPolicy{Hours: 0, Action: domain.SLAReassign}},
+		{domain.TeamSettings{TeamName: "backend", ReviewerCount: 2, SLAAction: domain.SLAEscalate}, domain.SLAPolicy{Hours: 24, Action: domain.SLAEscalate}},
+	} {
+		if err := s.Teams.UpsertSettings(ctx, s.DB, tc.settings); err != nil {
+			t.Fatal(err)
+		}
+		assignments, err := s.PRs.GetAssignments(ctx, s.DB, "pr-1", defaultSLA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a := assignments[0]; a.SLAHours != tc.want.Hours || a.SLAAction != tc.want.Action {
+			t.Errorf("assignment with settings %+v = %+v, want %+v", tc.settings, a, tc.want)
+		}
+	}
+
+	err = s.Teams.UpsertSettings(ctx, s.DB, domain.TeamSettings{
+		TeamName: "backend", ReviewerCount: 2, SLAHours: intPtr(2), SLAAction: domain.SLAEscalate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assignments, err = s.PRs.GetAssignments(ctx, s.DB, "pr-1", defaultSLA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := assignments[0]; a.SLAHours != 2 || a.SLAAction != domain.SLAEscalate {
+		t.Errorf("assignment = %+v, want the team SLA", a)
+	}
+
+	overdue, err := s.PRs.GetOverdueAssignments(ctx, s.DB, defaultSLA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overdue) != 0 {
+		t.Errorf("overdue = %+v right after assignment, want none", overdue)
+	}
+
+	// Просрочку забирает только первый вызов
+	if ok, err := s.PRs.MarkEscalated(ctx, s.DB, "pr-1", "u2"); err != nil || !ok {
+		t.Errorf("first MarkEscalated = %v, %v, want true", ok, err)
+	}
+	if ok, err := s.PRs.MarkEscalated(ctx, s.DB, "pr-1", "u2"); err != nil || ok {
+		t.Errorf("second MarkEscalated = %v, %v, want false", ok, err)
+	}
+
+	// Перезапуск SLA снимает отметку об эскалации
+	if err := s.PRs.RestartAssignments(ctx, s.DB, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.PRs.MarkEscalated(ctx, s.DB, "pr-1", "u2"); err != nil || !ok {
+		t.Errorf("MarkEscalated after restart = %v, %v, want true", ok, err)
+	}
+
+	// После первого решения ревьюера просрочки нет
+	if err := s.PRs.RestartAssignments(ctx, s.DB, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PRs.AddReview(ctx, s.DB, domain.Review{PullRequestID: "pr-1", ReviewerID: "u2", Decision: domain.ReviewCommented}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.PRs.MarkEscalated(ctx, s.DB, "pr-1", "u2"); err != nil || ok {
+		t.Errorf("MarkEscalated after review = %v, %v, want false", ok, err)
+	}
+}
+
+func testClaimDeliveries(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	sub, err := s.Hooks.CreateSubscription(ctx, s.DB, domain.WebhookSubscription{URL: "http://example.test/hook", Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Hooks.AddEvent(ctx, s.DB, domain.EventPRCreated, []byte(`{"pull_request_id":"pr-1"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.Hooks.FanOutEvents(ctx, s.DB, 10); err != nil || n != 1 {
+		t.Fatalf("FanOutEvents = %d, %v, want 1", n, err)
+	}
+
+	claimed, err := s.Hooks.ClaimDeliveries(ctx, s.DB, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", len(claimed))
+	}
+	d := claimed[0]
+	if d.SubscriptionID != sub.ID || d.EventType != domain.EventPRCreated || d.Attempts != 1 || d.URL != sub.URL || d.Secret != "s3cret" {
+		t.Errorf("claimed delivery = %+v", d)
+	}
+
+	// Пока аренда не истекла, доставка не выдаётся повторно
+	again, err := s.Hooks.ClaimDeliveries(ctx, s.DB, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("claimed %d leased deliveries, want 0", len(again))
+	}
+
+	now := time.Now()
+	d.Status = domain.DeliveryDelivered
+	d.DeliveredAt = &now
+	if err := s.Hooks.CompleteDelivery(ctx, s.DB, d); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, err := s.Hooks.ListDeliveries(ctx, s.DB, sub.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != domain.DeliveryDelivered || deliveries[0].DeliveredAt == nil {
+		t.Errorf("deliveries = %+v, want one delivered", deliveries)
+	}
+}
+
+func testTokens(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	created, err := s.Tokens.Create(ctx, s.DB, domain.APIToken{Name: "ci", Role: domain.RoleService}, "hash-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.Tokens.GetByHash(ctx, s.DB, "hash-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == nil || token.ID != created.ID || token.Name != "ci" || token.Role != domain.RoleService || token.RevokedAt != nil {
+		t.Fatalf("token = %+v, want active ci service token", token)
+	}
+
+	if ok, err := s.Tokens.Revoke(ctx, s.DB, created.ID); err != nil || !ok {
+		t.Fatalf("Revoke = %v, %v, want true", ok, err)
+	}
+	token, err = s.Tokens.GetByHash(ctx, s.DB, "hash-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == nil || token.RevokedAt == nil {
+		t.Errorf("token = %+v, want revoked", token)
+	}
+
+	missing, err := s.Tokens.GetByHash(ctx, s.DB, "hash-404")
+	if err != nil || missing != nil {
+		t.Errorf("GetByHash(hash-404) = %+v, %v, want nil, nil", missing, err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package sqlite реализует репозитории поверх SQLite — для небольших
+// установок, где сервис работает одним бинарником без PostgreSQL.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"pr-reviewer/internal/repository"
+	"pr-reviewer/migrations"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite"
+)
+
+// DB — хранилище в файле SQLite.
+type DB struct {
+	*sql.DB
+}
+
+// Open открывает файл базы, создавая его при необходимости, и применяет
+// недостающие части встроенной схемы.
+func Open(ctx context.Context, path string) (*DB, error) {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	// Время пишется в формате SQLite, такие строки сравниваются по порядку
+	query.Set("_time_format", "sqlite")
+
+	db, err := otelsql.Open("sqlite", "file:"+path+"?"+query.Encode(),
+		otelsql.WithAttributes(semconv.DBSystemSqlite),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite допускает одного писателя; одно соединение на процесс исключает
+	// ошибки SQLITE_BUSY между транзакциями сервиса
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{DB: db}, nil
+}
+
+const backend = "sqlite"
+
+func (db *DB) Backend() string {
+	return backend
+}
+
+func (db *DB) BeginTx(ctx context.Context) (repository.Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+// Tx — транзакция SQLite. Rollback после Commit ничего не делает.
+type Tx struct {
+	*sql.Tx
+}
+
+func (tx *Tx) Backend() string {
+	return backend
+}
+
+func (tx *Tx) Rollback() error {
+	if err := tx.Tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
+
+// SchemaVersion возвращает номер последней применённой части схемы.
+func (db *DB) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+type schemaFile struct {
+	version int
+	name    string
+}
+
+// migrate применяет файлы схемы с номером больше PRAGMA user_version.
+// Каждый файл выполняется в своей транзакции вместе с обновлением версии.
+func migrate(ctx context.Context, db *sql.DB) error {
+	entries, err := fs.ReadDir(migrations.SQLiteFS, "sqlite")
+	if err != nil {
+		return fmt.Errorf("failed to read embedded sqlite schema: %w", err)
+	}
+
+	var files []schemaFile
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid sqlite schema file name %q", e.Name())
+		}
+		files = append(files, schemaFile{version: version, name: e.Name()})
+	}
+	slices.SortFunc(files, func(a, b schemaFile) int { return a.version - b.version })
+
+	var current int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&current); err != nil {
+		return fmt.Errorf("failed to read sqlite schema version: %w", err)
+	}
+
+	for _, f := range files {
+		if f.version <= current {
+			continue
+		}
+		if err := applySchemaFile(ctx, db, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySchemaFile(ctx context.Context, db *sql.DB, f schemaFile) error {
+	body, err := fs.ReadFile(migrations.SQLiteFS, "sqlite/"+f.name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+		return fmt.Errorf("failed to apply sqlite schema %s: %w", f.name, err)
+	}
+	// PRAGMA не принимает параметры, версия — число из имени файла
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", f.version)); err != nil {
+		return fmt.Errorf("failed to update sqlite schema version: %w", err)
+	}
+	return tx.Commit()
+}
+
+// sqlQuerier — общие методы *sql.DB и *sql.Tx.
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn возвращает SQL-подключение, стоящее за db.
+func conn(db repository.Querier) (sqlQuerier, error) {
+	switch db := db.(type) {
+	case *DB:
+		return db.DB, nil
+	case *Tx:
+		return db.Tx, nil
+	}
+	return nil, fmt.Errorf("%w: %s repository got %T", repository.ErrUnsupportedQuerier, backend, db)
+}
+
+func execContext(ctx context.Context, db repository.Querier, query string, args ...any) (sql.Result, error) {
+	q, err := conn(db)
+	if err != nil {
+		return nil, err
+	}
+	return q.ExecContext(ctx, query, args...)
+}
+
+func queryContext(ctx context.Context, db repository.Querier, query string, args ...any) (*sql.Rows, error) {
+	q, err := conn(db)
+	if err != nil {
+		return nil, err
+	}
+	return q.QueryContext(ctx, query, args...)
+}
+
+// row — строка результата; ошибку подключения возвращает её Scan.
+type row interface {
+	Scan(dest ...any) error
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+func queryRowContext(ctx context.Context, db repository.Querier, query string, args ...any) row {
+	q, err := conn(db)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return q.QueryRowContext(ctx, query, args...)
+}
+
+// now возвращает текущее время в UTC: SQLite сравнивает время как строки,
+// поэтому все значения пишутся в одном часовом поясе.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// inTx выполняет fn в транзакции: в уже открытой, если db — транзакция,
+// иначе в новой, которая фиксируется при успехе fn.
+func inTx(ctx context.Context, db repository.Querier, fn func(tx sqlQuerier) error) error {
+	sqlDB, ok := db.(*DB)
+	if !ok {
+		q, err := conn(db)
+		if err != nil {
+			return err
+		}
+		return fn(q)
+	}
+
+	tx, err := sqlDB.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
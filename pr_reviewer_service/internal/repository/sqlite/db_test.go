@@ -0,0 +1,97 @@
+package sqlite_test
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository/repotest"
+	"pr-reviewer/internal/repository/sqlite"
+	"pr-reviewer/migrations"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func open(t *testing.T, path string) *sqlite.DB {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Storage {
+		return repotest.Storage{
+			DB:     open(t, filepath.Join(t.TempDir(), "reviewer.db")),
+			Teams:  sqlite.NewTeamRepo(),
+			Users:  sqlite.NewUserRepo(),
+			PRs:    sqlite.NewPRRepo(),
+			Hooks:  sqlite.NewWebhookRepo(),
+			Tokens: sqlite.NewTokenRepo(),
+			Owners: sqlite.NewCodeOwnersRepo(),
+		}
+	})
+}
+
+// latestSchemaVersion возвращает номер последнего встроенного файла схемы.
+func latestSchemaVersion(t *testing.T) int {
+	t.Helper()
+	entries, err := fs.ReadDir(migrations.SQLiteFS, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var latest int
+	for _, e := range entries {
+		prefix, _, _ := strings.Cut(e.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			t.Fatalf("invalid schema file name %q", e.Name())
+		}
+		latest = max(latest, version)
+	}
+	return latest
+}
+
+func TestOpenAppliesSchemaOnce(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "reviewer.db")
+	want := latestSchemaVersion(t)
+
+	db := open(t, path)
+	version, err := db.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != want {
+		t.Fatalf("user_version = %d, want %d", version, want)
+	}
+	if err := sqlite.NewTeamRepo().Create(ctx, db, domain.Team{Name: "backend"}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Повторное открытие не применяет схему заново и сохраняет данные
+	db = open(t, path)
+	version, err = db.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != want {
+		t.Errorf("user_version after reopen = %d, want %d", version, want)
+	}
+	ok, err := sqlite.NewTeamRepo().Exists(ctx, db, "backend")
+	if err != nil || !ok {
+		t.Errorf("Exists after reopen = %v, %v, want true", ok, err)
+	}
+}
+
+func TestOpenUsesSingleConnection(t *testing.T) {
+	db := open(t, filepath.Join(t.TempDir(), "reviewer.db"))
+	if n := db.Stats().MaxOpenConnections; n != 1 {
+		t.Errorf("max open connections = %d, want 1", n)
+	}
+}
@@ -0,0 +1,434 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+)
+
+type PRRepo struct{}
+
+func NewPRRepo() *PRRepo {
+	return &PRRepo{}
+}
+
+func (r *PRRepo) Exists(ctx context.Context, db repository.Querier, id string) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE id = ?)"
+	err := queryRowContext(ctx, db, query, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *PRRepo) Create(ctx context.Context, db repository.Querier, pr domain.PullRequest) error {
+	queryPR := `INSERT INTO pull_requests (id, name, author_id, status, created_at) VALUES (?, ?, ?, ?, ?)`
+	createdAt := now()
+	_, err := execContext(ctx, db, queryPR, pr.ID, pr.Name, pr.AuthorID, pr.Status, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert PR: %w", err)
+	}
+
+	if len(pr.Reviewers) > 0 {
+		queryRev := `INSERT INTO pull_requests_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES (?, ?, ?)`
+		for _, reviewer := range pr.Reviewers {
+			_, err := execContext(ctx, db, queryRev, pr.ID, reviewer.ID, createdAt)
+			if err != nil {
+				return fmt.Errorf("failed to insert reviewer %s: %w", reviewer.ID, err)
+			}
+		}
+	}
+
+	if len(pr.Labels) > 0 {
+		queryLabel := `INSERT INTO pull_request_labels (pull_request_id, label) VALUES (?, ?) ON CONFLICT DO NOTHING`
+		for _, label := range pr.Labels {
+			if _, err := execContext(ctx, db, queryLabel, pr.ID, label); err != nil {
+				return fmt.Errorf("failed to insert label %s: %w", label, err)
+			}
+		}
+	}
+
+	if len(pr.ChangedFiles) > 0 {
+		queryFile := `INSERT INTO pull_request_files (pull_request_id, path) VALUES (?, ?) ON CONFLICT DO NOTHING`
+		for _, path := range pr.ChangedFiles {
+			if _, err := execContext(ctx, db, queryFile, pr.ID, path); err != nil {
+				return fmt.Errorf("failed to insert changed file %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *PRRepo) GetByID(ctx context.Context, db repository.Querier, id string) (*domain.PullRequest, error) {
+	queryPR := `
+		SELECT id, name, author_id, status, created_at, merged_at, closed_at
+		FROM pull_requests 
+		WHERE id = ?
+	`
+	var pr domain.PullRequest
+	var createdAt sql.NullTime
+	var mergedAt sql.NullTime
+	var closedAt sql.NullTime
+
+	err := queryRowContext(ctx, db, queryPR, id).Scan(
+		&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt, &closedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	pr.CreatedAt = createdAt.Time
+	if mergedAt.Valid {
+		pr.MergedAt = &mergedAt.Time
+	} else {
+		pr.MergedAt = nil
+	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
+
+	queryReviewers := `
+		SELECT u.id, u.username, u.is_active, u.team_name
+		FROM users u
+		JOIN pull_requests_reviewers prr ON u.id = prr.reviewer_id
+		WHERE prr.pull_request_id = ?
+	`
+	rows, err := queryContext(ctx, db, queryReviewers, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName); err != nil {
+			return nil, err
+		}
+		pr.Reviewers = append(pr.Reviewers, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryFiles := "SELECT path FROM pull_request_files WHERE pull_request_id = ? ORDER BY path"
+	fileRows, err := queryContext(ctx, db, queryFiles, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	defer fileRows.Close()
+
+	for fileRows.Next() {
+		var path string
+		if err := fileRows.Scan(&path); err != nil {
+			return nil, err
+		}
+		pr.ChangedFiles = append(pr.ChangedFiles, path)
+	}
+	if err := fileRows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryLabels := "SELECT label FROM pull_request_labels WHERE pull_request_id = ? ORDER BY label"
+	labelRows, err := queryContext(ctx, db, queryLabels, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+	defer labelRows.Close()
+
+	for labelRows.Next() {
+		var label string
+		if err := labelRows.Scan(&label); err != nil {
+			return nil, err
+		}
+		pr.Labels = append(pr.Labels, label)
+	}
+	if err := labelRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+func (r *PRRepo) SetStatus(ctx context.Context, db repository.Querier, id string, status domain.PRStatus) error {
+	var err error
+	switch status {
+	case domain.PRStatusMerged:
+		_, err = execContext(ctx, db, "UPDATE pull_requests SET status = ?, merged_at = ? WHERE id = ?", status, now(), id)
+	case domain.PRStatusClosed:
+		_, err = execContext(ctx, db, "UPDATE pull_requests SET status = ?, closed_at = ? WHERE id = ?", status, now(), id)
+	default:
+		_, err = execContext(ctx, db, "UPDATE pull_requests SET status = ?, closed_at = NULL WHERE id = ?", status, id)
+	}
+	return err
+}
+
+func (r *PRRepo) ReplaceReviewer(ctx context.Context, db repository.Querier, prID, oldReviewerID, newReviewerID string) error {
+	queryDel := "DELETE FROM pull_requests_reviewers WHERE pull_request_id = ? AND reviewer_id = ?"
+	_, err := execContext(ctx, db, queryDel, prID, oldReviewerID)
+	if err != nil {
+		return err
+	}
+
+	queryIns := "INSERT INTO pull_requests_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES (?, ?, ?)"
+	_, err = execContext(ctx, db, queryIns, prID, newReviewerID, now())
+	return err
+}
+
+func (r *PRRepo) AddReviewer(ctx context.Context, db repository.Querier, prID, reviewerID string) error {
+	query := "INSERT INTO pull_requests_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES (?, ?, ?)"
+	_, err := execContext(ctx, db, query, prID, reviewerID, now())
+	return err
+}
+
+func (r *PRRepo) RemoveReviewer(ctx context.Context, db repository.Querier, prID, reviewerID string) error {
+	query := "DELETE FROM pull_requests_reviewers WHERE pull_request_id = ? AND reviewer_id = ?"
+	_, err := execContext(ctx, db, query, prID, reviewerID)
+	return err
+}
+
+// GetByReviewerID возвращает открытые PR, на которые назначен ревьюер.
+func (r *PRRepo) GetByReviewerID(ctx context.Context, db repository.Querier, reviewerID string) ([]domain.PullRequestShort, error) {
+	query := `
+		SELECT pr.id, pr.name, pr.author_id, pr.status
+		FROM pull_requests pr
+		JOIN pull_requests_reviewers prr ON pr.id = prr.pull_request_id
+		WHERE prr.reviewer_id = ? AND pr.status = 'OPEN'
+		ORDER BY pr.created_at, pr.id
+	`
+
+	rows, err := queryContext(ctx, db, query, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user reviews: %w", err)
+	}
+	defer rows.Close()
+
+	// Инициализируем как пустой слайс, чтобы JSON был [], если записей нет
+	result := []domain.PullRequestShort{}
+
+	for rows.Next() {
+		var pr domain.PullRequestShort
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status); err != nil {
+			return nil, err
+		}
+		result = append(result, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *PRRepo) AddReview(ctx context.Context, db repository.Querier, review domain.Review) (*domain.Review, error) {
+	query := `
+		INSERT INTO pull_request_reviews (pull_request_id, reviewer_id, decision, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	review.CreatedAt = now()
+	_, err := execContext(ctx, db, query, review.PullRequestID, review.ReviewerID, review.Decision, review.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert review: %w", err)
+	}
+
+	queryAction := `
+		UPDATE pull_requests_reviewers
+		SET first_action_at = ?
+		WHERE pull_request_id = ? AND reviewer_id = ? AND first_action_at IS NULL
+	`
+	if _, err := execContext(ctx, db, queryAction, review.CreatedAt, review.PullRequestID, review.ReviewerID); err != nil {
+		return nil, fmt.Errorf("failed to record first review action: %w", err)
+	}
+	return &review, nil
+}
+
+// GetReviews возвращает историю решений по PR в хронологическом порядке.
+func (r *PRRepo) GetReviews(ctx context.Context, db repository.Querier, prID string) ([]domain.Review, error) {
+	query := `
+		SELECT pull_request_id, reviewer_id, decision, created_at
+		FROM pull_request_reviews
+		WHERE pull_request_id = ?
+		ORDER BY created_at, id
+	`
+	rows, err := queryContext(ctx, db, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []domain.Review{}
+	for rows.Next() {
+		var rv domain.Review
+		if err := rows.Scan(&rv.PullRequestID, &rv.ReviewerID, &rv.Decision, &rv.CreatedAt); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *PRRepo) AddMergeOverride(ctx context.Context, db repository.Querier, override domain.MergeOverride) error {
+	query := `
+		INSERT INTO merge_overrides (pull_request_id, forced_by, reason, unmet_policy, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := execContext(ctx, db, query, override.PullRequestID, override.ForcedBy, override.Reason, override.UnmetPolicy, now())
+	if err != nil {
+		return fmt.Errorf("failed to insert merge override: %w", err)
+	}
+	return nil
+}
+
+func (r *PRRepo) GetAssignments(ctx context.Context, db repository.Querier, prID string, defaultSLA domain.SLAPolicy) ([]domain.ReviewAssignment, error) {
+	query := `
+		SELECT prr.pull_request_id, prr.reviewer_id, prr.assigned_at, prr.first_action_at, u.team_name,
+			COALESCE(ts.sla_hours, ?), COALESCE(ts.sla_action, ?)
+		FROM pull_requests_reviewers prr
+		JOIN pull_requests pr ON pr.id = prr.pull_request_id
+		JOIN users u ON u.id = pr.author_id
+		LEFT JOIN team_settings ts ON ts.team_name = u.team_name
+		WHERE prr.pull_request_id = ?
+		ORDER BY prr.assigned_at
+	`
+	rows, err := queryContext(ctx, db, query, defaultSLA.Hours, defaultSLA.Action, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []domain.ReviewAssignment{}
+	for rows.Next() {
+		var a domain.ReviewAssignment
+		var firstActionAt sql.NullTime
+		if err := rows.Scan(&a.PullRequestID, &a.ReviewerID, &a.AssignedAt, &firstActionAt, &a.TeamName, &a.SLAHours, &a.SLAAction); err != nil {
+			return nil, err
+		}
+		if firstActionAt.Valid {
+			a.FirstActionAt = &firstActionAt.Time
+		}
+		assignments = append(assignments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// GetOverdueAssignments возвращает назначения на открытые PR, по которым ревьюер
+// не отреагировал дольше SLA команды автора и которые ещё не эскалировались.
+// Для команд без сохранённых настроек действует defaultSLA.
+func (r *PRRepo) GetOverdueAssignments(ctx context.Context, db repository.Querier, defaultSLA domain.SLAPolicy) ([]domain.ReviewAssignment, error) {
+	query := `
+		SELECT a.pull_request_id, a.reviewer_id, a.assigned_at, a.team_name, a.sla_hours, a.sla_action
+		FROM (
+			SELECT prr.pull_request_id, prr.reviewer_id, prr.assigned_at, u.team_name,
+				COALESCE(ts.sla_hours, ?) AS sla_hours, COALESCE(ts.sla_action, ?) AS sla_action
+			FROM pull_requests_reviewers prr
+			JOIN pull_requests pr ON pr.id = prr.pull_request_id
+			JOIN users u ON u.id = pr.author_id
+			LEFT JOIN team_settings ts ON ts.team_name = u.team_name
+			WHERE pr.status = 'OPEN'
+				AND prr.first_action_at IS NULL
+				AND prr.escalated_at IS NULL
+		) a
+		WHERE a.sla_hours > 0
+			AND julianday(a.assigned_at) + a.sla_hours / 24.0 < julianday(?)
+		ORDER BY a.assigned_at
+	`
+	rows, err := queryContext(ctx, db, query, defaultSLA.Hours, defaultSLA.Action, now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []domain.ReviewAssignment{}
+	for rows.Next() {
+		var a domain.ReviewAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.ReviewerID, &a.AssignedAt, &a.TeamName, &a.SLAHours, &a.SLAAction); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// MarkEscalated отмечает назначение эскалированным, только если оно ещё не
+// эскалировано и ревьюер не ответил. Условный UPDATE не даёт двум репликам
+// обработать одно назначение: false означает, что его уже забрали.
+func (r *PRRepo) MarkEscalated(ctx context.Context, db repository.Querier, prID, reviewerID string) (bool, error) {
+	query := `
+		UPDATE pull_requests_reviewers
+		SET escalated_at = ?
+		WHERE pull_request_id = ? AND reviewer_id = ?
+			AND escalated_at IS NULL AND first_action_at IS NULL
+	`
+	res, err := execContext(ctx, db, query, now(), prID, reviewerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark assignment escalated: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RestartAssignments заново отсчитывает SLA для ревьюеров, ещё не ответивших по PR.
+func (r *PRRepo) RestartAssignments(ctx context.Context, db repository.Querier, prID string) error {
+	query := `
+		UPDATE pull_requests_reviewers
+		SET assigned_at = ?, escalated_at = NULL
+		WHERE pull_request_id = ? AND first_action_at IS NULL
+	`
+	_, err := execContext(ctx, db, query, now(), prID)
+	if err != nil {
+		return fmt.Errorf("failed to restart assignments: %w", err)
+	}
+	return nil
+}
+
+// CountOpenReviews возвращает число открытых PR на ревью у каждого ревьюера.
+func (r *PRRepo) CountOpenReviews(ctx context.Context, db repository.Querier) (map[string]int, error) {
+	query := `
+		SELECT prr.reviewer_id, COUNT(*)
+		FROM pull_requests_reviewers prr
+		JOIN pull_requests pr ON pr.id = prr.pull_request_id
+		WHERE pr.status = 'OPEN'
+		GROUP BY prr.reviewer_id
+	`
+	rows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var reviewerID string
+		var count int
+		if err := rows.Scan(&reviewerID, &count); err != nil {
+			return nil, err
+		}
+		result[reviewerID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
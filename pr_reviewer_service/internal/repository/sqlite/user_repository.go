@@ -0,0 +1,286 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/repository"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// userTagsColumn собирает теги пользователя u в одну строку через запятую.
+const userTagsColumn = `COALESCE((SELECT string_agg(t.tag, ',' ORDER BY t.tag) FROM user_tags t WHERE t.user_id = u.id), '')`
+
+func splitTags(raw string) []string {
+	if raw == "" {
+		return []string{}
+	}
+	return strings.Split(raw, ",")
+}
+
+type UserRepo struct{}
+
+func NewUserRepo() *UserRepo {
+	return &UserRepo{}
+}
+
+func (r *UserRepo) Upsert(ctx context.Context, db repository.Querier, users []domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	valueStrings := make([]string, 0, len(users))
+	valueArgs := make([]any, 0, len(users)*4)
+
+	for _, u := range users {
+		valueStrings = append(valueStrings, "(?, ?, ?, ?)")
+		valueArgs = append(valueArgs, u.ID, u.Username, u.IsActive, u.TeamName)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO users (id, username, is_active, team_name)
+		VALUES %s
+		ON CONFLICT (id) DO UPDATE
+		SET username = excluded.username,
+			is_active = excluded.is_active,
+			team_name = excluded.team_name
+	`, strings.Join(valueStrings, ","))
+
+	_, err := execContext(ctx, db, query, valueArgs...)
+	return err
+}
+
+// SetIsActive обновляет флаг и перечитывает пользователя: RETURNING в SQLite
+// не поддерживает коррелированный подзапрос тегов.
+func (r *UserRepo) SetIsActive(ctx context.Context, db repository.Querier, userID string, isActive bool) (*domain.User, error) {
+	res, err := execContext(ctx, db, "UPDATE users SET is_active = ? WHERE id = ?", isActive, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user active status: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+	return r.GetByID(ctx, db, userID)
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, db repository.Querier, userID string) (*domain.User, error) {
+	query := "SELECT u.id, u.username, u.is_active, u.team_name, " + userTagsColumn + " FROM users u WHERE u.id = ?"
+	var u domain.User
+	var tags string
+	err := queryRowContext(ctx, db, query, userID).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &tags)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	u.Tags = splitTags(tags)
+	return &u, nil
+}
+
+const candidatesQuery = `
+	SELECT u.id, u.username, u.is_active, u.team_name, ` + userTagsColumn + `, COUNT(pr.id)
+	FROM users u
+	LEFT JOIN pull_requests_reviewers prr ON prr.reviewer_id = u.id
+	LEFT JOIN pull_requests pr ON pr.id = prr.pull_request_id AND pr.status = 'OPEN'
+	WHERE u.is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM user_absences a
+			WHERE a.user_id = u.id AND a.starts_at <= ? AND a.ends_at > ?
+		)`
+
+const candidatesGroupBy = " GROUP BY u.id, u.username, u.is_active, u.team_name"
+
+func (r *UserRepo) GetActiveCandidates(ctx context.Context, db repository.Querier, teamName string, excludeUserIDs []string) ([]domain.Candidate, error) {
+	query := candidatesQuery + " AND u.team_name = ?"
+	// Первые два параметра — текущее время для проверки отсутствий
+	t := now()
+	args := []any{t, t, teamName}
+
+	if len(excludeUserIDs) > 0 {
+		query += fmt.Sprintf(" AND u.id NOT IN (%s)", placeholders(len(excludeUserIDs)))
+		for _, id := range excludeUserIDs {
+			args = append(args, id)
+		}
+	}
+	query += candidatesGroupBy
+
+	return r.queryCandidates(ctx, db, query, args)
+}
+
+func (r *UserRepo) GetActiveCandidatesByIDs(ctx context.Context, db repository.Querier, userIDs []string, excludeUserIDs []string) ([]domain.Candidate, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	query := candidatesQuery + fmt.Sprintf(" AND u.id IN (%s)", placeholders(len(userIDs)))
+	t := now()
+	args := make([]any, 0, 2+len(userIDs)+len(excludeUserIDs))
+	args = append(args, t, t)
+	for _, id := range userIDs {
+		args = append(args, id)
+	}
+
+	if len(excludeUserIDs) > 0 {
+		query += fmt.Sprintf(" AND u.id NOT IN (%s)", placeholders(len(excludeUserIDs)))
+		for _, id := range excludeUserIDs {
+			args = append(args, id)
+		}
+	}
+	query += candidatesGroupBy
+
+	return r.queryCandidates(ctx, db, query, args)
+}
+
+func (r *UserRepo) queryCandidates(ctx context.Context, db repository.Querier, query string, args []any) ([]domain.Candidate, error) {
+	rows, err := queryContext(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []domain.Candidate
+	for rows.Next() {
+		var c domain.Candidate
+		var tags string
+		if err := rows.Scan(&c.ID, &c.Username, &c.IsActive, &c.TeamName, &tags, &c.OpenReviews); err != nil {
+			return nil, err
+		}
+		c.Tags = splitTags(tags)
+		candidates = append(candidates, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("Loaded reviewer candidates", zap.Int("count", len(candidates)))
+	return candidates, nil
+}
+
+func (r *UserRepo) SetTags(ctx context.Context, db repository.Querier, userID string, tags []string) error {
+	if _, err := execContext(ctx, db, "DELETE FROM user_tags WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to clear user tags: %w", err)
+	}
+
+	query := "INSERT INTO user_tags (user_id, tag) VALUES (?, ?) ON CONFLICT DO NOTHING"
+	for _, tag := range tags {
+		if _, err := execContext(ctx, db, query, userID, tag); err != nil {
+			return fmt.Errorf("failed to insert user tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func (r *UserRepo) AddAbsence(ctx context.Context, db repository.Querier, absence domain.Absence) (*domain.Absence, error) {
+	query := `
+		INSERT INTO user_absences (user_id, starts_at, ends_at, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err := queryRowContext(ctx, db, query,
+		absence.UserID, absence.StartsAt.UTC(), absence.EndsAt.UTC(), absence.Reason, now(),
+	).Scan(&absence.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert absence: %w", err)
+	}
+	return &absence, nil
+}
+
+// ListAbsences возвращает текущие и будущие отсутствия пользователя.
+func (r *UserRepo) ListAbsences(ctx context.Context, db repository.Querier, userID string) ([]domain.Absence, error) {
+	query := `
+		SELECT id, user_id, starts_at, ends_at, reason
+		FROM user_absences
+		WHERE user_id = ? AND ends_at > ?
+		ORDER BY starts_at
+	`
+	rows, err := queryContext(ctx, db, query, userID, now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list absences: %w", err)
+	}
+	defer rows.Close()
+
+	absences := []domain.Absence{}
+	for rows.Next() {
+		var a domain.Absence
+		if err := rows.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason); err != nil {
+			return nil, err
+		}
+		absences = append(absences, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return absences, nil
+}
+
+func (r *UserRepo) DeleteAbsence(ctx context.Context, db repository.Querier, userID string, absenceID int64) (bool, error) {
+	res, err := execContext(ctx, db, "DELETE FROM user_absences WHERE id = ? AND user_id = ?", absenceID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete absence: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// placeholders возвращает "?, ?, ..." для n параметров.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// SetExternalLogin привязывает логин к пользователю, снимая прежние привязки
+// этого логина и этого пользователя у того же провайдера.
+func (r *UserRepo) SetExternalLogin(ctx context.Context, db repository.Querier, account domain.ExternalAccount) error {
+	_, err := execContext(ctx, db,
+		"DELETE FROM user_external_accounts WHERE provider = ? AND (login = ? OR user_id = ?)",
+		account.Provider, account.Login, account.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear external account: %w", err)
+	}
+
+	_, err = execContext(ctx, db,
+		"INSERT INTO user_external_accounts (provider, login, user_id) VALUES (?, ?, ?)",
+		account.Provider, account.Login, account.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert external account: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepo) GetByExternalLogin(ctx context.Context, db repository.Querier, provider domain.Provider, login string) (*domain.User, error) {
+	query := `
+		SELECT u.id, u.username, u.is_active, u.team_name, ` + userTagsColumn + `
+		FROM user_external_accounts ea
+		JOIN users u ON u.id = ea.user_id
+		WHERE ea.provider = ? AND ea.login = ?
+	`
+	var u domain.User
+	var tags string
+	err := queryRowContext(ctx, db, query, provider, login).Scan(&u.ID, &u.Username, &u.IsActive, &u.TeamName, &tags)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by external login: %w", err)
+	}
+	u.Tags = splitTags(tags)
+	return &u, nil
+}
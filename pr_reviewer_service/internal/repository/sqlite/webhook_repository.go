@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"strings"
+	"time"
+)
+
+type WebhookRepo struct{}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{}
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, db repository.Querier, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, events, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, is_active, created_at
+	`
+	err := queryRowContext(ctx, db, query, sub.URL, sub.Secret, joinEvents(sub.Events), now()).Scan(&sub.ID, &sub.IsActive, &sub.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context, db repository.Querier) ([]domain.WebhookSubscription, error) {
+	query := "SELECT id, url, secret, events, is_active, created_at FROM webhook_subscriptions ORDER BY id"
+	rows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []domain.WebhookSubscription{}
+	for rows.Next() {
+		var sub domain.WebhookSubscription
+		var events string
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &events, &sub.IsActive, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		sub.Events = splitEvents(events)
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, db repository.Querier, id int64) (bool, error) {
+	res, err := execContext(ctx, db, "DELETE FROM webhook_subscriptions WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *WebhookRepo) AddEvent(ctx context.Context, db repository.Querier, eventType domain.EventType, payload []byte) error {
+	query := "INSERT INTO outbox_events (event_type, payload, created_at) VALUES (?, ?, ?)"
+	if _, err := execContext(ctx, db, query, eventType, string(payload), now()); err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// pendingEvents выбирает первые неразосланные события; параметр — их число.
+const pendingEvents = "SELECT id FROM outbox_events WHERE dispatched_at IS NULL ORDER BY id LIMIT ?"
+
+// FanOutEvents создаёт доставки неразосланных событий для всех подходящих
+// активных подписок и помечает события разосланными. SQLite не поддерживает
+// изменяющие CTE, поэтому шаги выполняются отдельными запросами в одной транзакции.
+func (r *WebhookRepo) FanOutEvents(ctx context.Context, db repository.Querier, limit int) (int, error) {
+	var count int
+	err := inTx(ctx, db, func(tx sqlQuerier) error {
+		t := now()
+		queryDeliveries := `
+			INSERT INTO webhook_deliveries (subscription_id, event_id, next_attempt_at, created_at)
+			SELECT s.id, e.id, ?, ?
+			FROM outbox_events e
+			JOIN webhook_subscriptions s
+				ON s.is_active AND (s.events = '' OR ',' || s.events || ',' LIKE '%,' || e.event_type || ',%')
+			WHERE e.id IN (` + pendingEvents + `)
+			ON CONFLICT DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, queryDeliveries, t, t, limit); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "UPDATE outbox_events SET dispatched_at = ? WHERE id IN ("+pendingEvents+")", t, limit)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		count = int(affected)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fan out outbox events: %w", err)
+	}
+	return count, nil
+}
+
+// ClaimDeliveries берёт в работу доставки, время попытки которых наступило.
+// Попытка засчитывается сразу, а следующая откладывается на lease.
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, db repository.Querier, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := inTx(ctx, db, func(tx sqlQuerier) error {
+		t := now()
+		query := `
+			SELECT d.id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts,
+				d.created_at, s.url, s.secret, e.payload
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			JOIN outbox_events e ON e.id = d.event_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= ?
+			ORDER BY d.next_attempt_at
+			LIMIT ?
+		`
+		rows, err := tx.QueryContext(ctx, query, t, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var d domain.WebhookDelivery
+			var payload string
+			err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+				&d.CreatedAt, &d.URL, &d.Secret, &payload)
+			if err != nil {
+				return err
+			}
+			d.Attempts++
+			d.NextAttemptAt = t.Add(lease)
+			d.Payload = []byte(payload)
+			deliveries = append(deliveries, d)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		queryClaim := "UPDATE webhook_deliveries SET attempts = ?, next_attempt_at = ? WHERE id = ?"
+		for _, d := range deliveries {
+			if _, err := tx.ExecContext(ctx, queryClaim, d.Attempts, d.NextAttemptAt, d.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) CompleteDelivery(ctx context.Context, db repository.Querier, d domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?2,
+			next_attempt_at = ?3,
+			response_status = ?4,
+			last_error = ?5,
+			delivered_at = ?6
+		WHERE id = ?1
+	`
+	var deliveredAt *time.Time
+	if d.DeliveredAt != nil {
+		t := d.DeliveredAt.UTC()
+		deliveredAt = &t
+	}
+	_, err := execContext(ctx, db, query, d.ID, d.Status, d.NextAttemptAt.UTC(), d.ResponseStatus, d.LastError, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки; subscriptionID = 0 — по всем подпискам.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, db repository.Querier, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at,
+			d.response_status, d.last_error, d.created_at, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.event_id
+		WHERE ?1 = 0 OR d.subscription_id = ?1
+		ORDER BY d.id DESC
+		LIMIT ?2
+	`
+	rows, err := queryContext(ctx, db, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var responseStatus sql.NullInt64
+		var lastError sql.NullString
+		var deliveredAt sql.NullTime
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&responseStatus, &lastError, &d.CreatedAt, &deliveredAt)
+		if err != nil {
+			return nil, err
+		}
+		if responseStatus.Valid {
+			code := int(responseStatus.Int64)
+			d.ResponseStatus = &code
+		}
+		if lastError.Valid {
+			d.LastError = &lastError.String
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func joinEvents(events []domain.EventType) string {
+	parts := make([]string, len(events))
+	for i, e := range events {
+		parts[i] = string(e)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitEvents(raw string) []domain.EventType {
+	events := []domain.EventType{}
+	if raw == "" {
+		return events
+	}
+	for _, e := range strings.Split(raw, ",") {
+		events = append(events, domain.EventType(e))
+	}
+	return events
+}
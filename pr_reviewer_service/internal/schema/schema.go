@@ -0,0 +1,171 @@
+// Package schema применяет встроенные миграции к PostgreSQL.
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"pr-reviewer/migrations"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/zap"
+)
+
+// autoMigrateLockID — ключ advisory-блокировки, под которой экземпляры сервиса
+// по очереди проверяют и применяют миграции при старте.
+const autoMigrateLockID = 7_245_310_021
+
+// Status — состояние схемы БД относительно встроенных миграций.
+type Status struct {
+	// Version — последняя применённая миграция, 0 — ни одной
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	// Latest — последняя встроенная в бинарник миграция
+	Latest uint `json:"latest"`
+}
+
+func (s Status) UpToDate() bool {
+	return !s.Dirty && s.Version >= s.Latest
+}
+
+// LatestVersion возвращает номер последней встроенной миграции.
+func LatestVersion() uint {
+	var latest uint
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		return 0
+	}
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest
+}
+
+// Migrator применяет миграции через собственное подключение к БД,
+// которое закрывается вызовом Close.
+type Migrator struct {
+	db *sql.DB
+	m  *migrate.Migrate
+}
+
+func New(dsn string) (*Migrator, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database driver: %w", err)
+	}
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	driver, err := pgx.WithInstance(db, &pgx.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+
+	return &Migrator{db: db, m: m}, nil
+}
+
+// Close закрывает подключение мигратора.
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func (mg *Migrator) Status() (Status, error) {
+	st := Status{Latest: LatestVersion()}
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return st, err
+	}
+	st.Version, st.Dirty = version, dirty
+	return st, nil
+}
+
+// Up применяет все непримененные миграции.
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down откатывает steps последних миграций.
+func (mg *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return ignoreNoChange(mg.m.Steps(-steps))
+}
+
+// Force записывает version как текущую версию и снимает флаг dirty, не выполняя
+// миграций. Нужен после ручного исправления схемы, упавшей посреди миграции.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// AutoMigrate применяет миграции при старте сервиса. Advisory-блокировка
+// гарантирует, что при одновременном запуске нескольких реплик миграции
+// выполняет только одна, а остальные дожидаются её и ничего не меняют.
+func AutoMigrate(ctx context.Context, dsn string, logger *zap.Logger) error {
+	mg, err := New(dsn)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	conn, err := mg.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	logger.Info("Waiting for migration lock")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", autoMigrateLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", autoMigrateLockID)
+
+	before, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if before.Dirty {
+		return fmt.Errorf("schema is dirty at version %d, fix it and run 'migrate force'", before.Version)
+	}
+	if before.UpToDate() {
+		logger.Info("Schema is up to date", zap.Uint("version", before.Version))
+		return nil
+	}
+
+	if err := mg.Up(); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	logger.Info("Applied migrations", zap.Uint("from", before.Version), zap.Uint("to", before.Latest))
+	return nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
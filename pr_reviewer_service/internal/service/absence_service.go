@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"pr-reviewer/internal/domain"
+)
+
+func (s *Service) ScheduleAbsence(ctx context.Context, absence domain.Absence) (*domain.Absence, error) {
+	ctx, span := startSpan(ctx, "ScheduleAbsence")
+	defer span.End()
+
+	if !absence.EndsAt.After(absence.StartsAt) {
+		return nil, ErrInvalidAbsence
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := s.repoUsers.GetByID(ctx, tx, absence.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	created, err := s.repoUsers.AddAbsence(ctx, tx, absence)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *Service) ListAbsences(ctx context.Context, userID string) ([]domain.Absence, error) {
+	ctx, span := startSpan(ctx, "ListAbsences")
+	defer span.End()
+
+	user, err := s.repoUsers.GetByID(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return s.repoUsers.ListAbsences(ctx, s.db, userID)
+}
+
+func (s *Service) CancelAbsence(ctx context.Context, userID string, absenceID int64) error {
+	ctx, span := startSpan(ctx, "CancelAbsence")
+	defer span.End()
+
+	deleted, err := s.repoUsers.DeleteAbsence(ctx, s.db, userID, absenceID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAbsenceNotFound
+	}
+	return nil
+}
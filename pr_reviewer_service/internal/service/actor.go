@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type actingUserKey struct{}
+
+// WithActingUser возвращает контекст, в котором методы сервиса выполняются
+// от имени пользователя userID — например, аутентифицированного по JWT.
+func WithActingUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actingUserKey{}, userID)
+}
+
+// ActingUser возвращает пользователя, от имени которого выполняется вызов.
+func ActingUser(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(actingUserKey{}).(string)
+	return userID, ok && userID != ""
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"strings"
+	"time"
+)
+
+// tokenPrefix отличает токены сервиса от прочих секретов, например в сканерах утечек.
+const tokenPrefix = "prr_"
+
+// hashToken возвращает хэш, под которым токен хранится в БД.
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueToken создаёт токен и возвращает его значение — оно показывается
+// только один раз, в БД остаётся лишь хэш.
+func (s *Service) IssueToken(ctx context.Context, token domain.APIToken) (string, *domain.APIToken, error) {
+	ctx, span := startSpan(ctx, "IssueToken")
+	defer span.End()
+
+	token.Name = strings.TrimSpace(token.Name)
+	if token.Name == "" {
+		return "", nil, fmt.Errorf("%w: name is required", ErrInvalidToken)
+	}
+	if !token.Role.Valid() {
+		return "", nil, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, token.Role)
+	}
+	if (token.TeamName != "") != (token.Role == domain.RoleTeamMaintainer) {
+		return "", nil, fmt.Errorf("%w: team_name is required for %s and not allowed for other roles", ErrInvalidToken, domain.RoleTeamMaintainer)
+	}
+	if (token.UserID != "") != (token.Role == domain.RoleUser) {
+		return "", nil, fmt.Errorf("%w: user_id is required for %s and not allowed for other roles", ErrInvalidToken, domain.RoleUser)
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return "", nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidToken)
+	}
+
+	if token.TeamName != "" {
+		exists, err := s.repoTeams.Exists(ctx, s.db, token.TeamName)
+		if err != nil {
+			return "", nil, err
+		}
+		if !exists {
+			return "", nil, ErrTeamNotFound
+		}
+	}
+	if token.UserID != "" {
+		user, err := s.repoUsers.GetByID(ctx, s.db, token.UserID)
+		if err != nil {
+			return "", nil, err
+		}
+		if user == nil {
+			return "", nil, ErrUserNotFound
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	raw := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	created, err := s.repoTokens.Create(ctx, s.db, token, hashToken(raw))
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, created, nil
+}
+
+func (s *Service) ListTokens(ctx context.Context) ([]domain.APIToken, error) {
+	ctx, span := startSpan(ctx, "ListTokens")
+	defer span.End()
+
+	return s.repoTokens.List(ctx, s.db)
+}
+
+func (s *Service) RevokeToken(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "RevokeToken")
+	defer span.End()
+
+	revoked, err := s.repoTokens.Revoke(ctx, s.db, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// Authenticate находит действующий токен по его значению.
+func (s *Service) Authenticate(ctx context.Context, raw string) (*domain.APIToken, error) {
+	ctx, span := startSpan(ctx, "Authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(raw, tokenPrefix) {
+		return nil, ErrUnauthenticated
+	}
+	token, err := s.repoTokens.GetByHash(ctx, s.db, hashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.Active(time.Now()) {
+		return nil, ErrUnauthenticated
+	}
+	return token, nil
+}
+
+// AuthenticateUser возвращает права пользователя, личность которого уже
+// подтверждена снаружи (JWT от OIDC-провайдера): он действует как токен роли user.
+func (s *Service) AuthenticateUser(ctx context.Context, userID string) (*domain.APIToken, error) {
+	ctx, span := startSpan(ctx, "AuthenticateUser")
+	defer span.End()
+
+	user, err := s.repoUsers.GetByID(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUnauthenticated
+	}
+	return &domain.APIToken{Name: "jwt", Role: domain.RoleUser, UserID: user.ID}, nil
+}
+
+// Проверки ниже ограничивают токены по данным запроса; допустимые роли
+// маршрута проверяет middleware. Администратор и сервисный токен
+// действуют во всех командах.
+
+// AuthorizeTeam разрешает мейнтейнеру изменять только свою команду.
+func (s *Service) AuthorizeTeam(ctx context.Context, token *domain.APIToken, teamName string) error {
+	switch token.Role {
+	case domain.RoleAdmin, domain.RoleService:
+		return nil
+	case domain.RoleTeamMaintainer:
+		if token.TeamName == teamName {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// AuthorizeUser разрешает мейнтейнеру изменять участников своей команды,
+// а пользователю — только себя.
+func (s *Service) AuthorizeUser(ctx context.Context, token *domain.APIToken, userID string) error {
+	switch token.Role {
+	case domain.RoleAdmin, domain.RoleService:
+		return nil
+	case domain.RoleUser:
+		if token.UserID == userID {
+			return nil
+		}
+		return ErrForbidden
+	}
+
+	user, err := s.repoUsers.GetByID(ctx, s.db, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return s.AuthorizeTeam(ctx, token, user.TeamName)
+}
+
+// AuthorizePR разрешает мейнтейнеру изменять PR авторов из своей команды.
+// Команда автора читается вне транзакции самого изменения: если автора в это
+// время переводят в другую команду, мейнтейнер прежней команды успеет изменить
+// PR ещё раз. Автор PR не меняется, а переводит пользователей между командами
+// только администратор через /team/add, поэтому это окно допустимо.
+func (s *Service) AuthorizePR(ctx context.Context, token *domain.APIToken, prID string) error {
+	switch token.Role {
+	case domain.RoleAdmin, domain.RoleService:
+		return nil
+	case domain.RoleUser:
+		return ErrForbidden
+	}
+
+	pr, err := s.repoPR.GetByID(ctx, s.db, prID)
+	if err != nil {
+		return err
+	}
+	if pr == nil {
+		return ErrPRNotFound
+	}
+	return s.AuthorizeUser(ctx, token, pr.AuthorID)
+}
+
+// AuthorizeReviewer разрешает администратору и мейнтейнеру команды автора PR
+// действовать за любого ревьюера, а остальным — только за себя: отказаться
+// от своего ревью или записать своё решение. Пустой reviewerID означает
+// пользователя, от имени которого выполняется вызов.
+func (s *Service) AuthorizeReviewer(ctx context.Context, token *domain.APIToken, prID, reviewerID string) error {
+	switch token.Role {
+	case domain.RoleAdmin, domain.RoleTeamMaintainer:
+		return s.AuthorizePR(ctx, token, prID)
+	}
+
+	if reviewerID == "" {
+		reviewerID, _ = ActingUser(ctx)
+	}
+	// У сервисного токена нет пользователя, поэтому ревьюером он выступать не может
+	if token.UserID == "" || token.UserID != reviewerID {
+		return ErrForbidden
+	}
+	return nil
+}
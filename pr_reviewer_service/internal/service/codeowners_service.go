@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/codeowners"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ImportCodeOwners заменяет все правила владения кодом правилами из файла CODEOWNERS.
+func (s *Service) ImportCodeOwners(ctx context.Context, content string) ([]domain.OwnershipRule, error) {
+	ctx, span := startSpan(ctx, "ImportCodeOwners")
+	defer span.End()
+
+	rules, err := codeowners.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCodeOwners, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, rule := range rules {
+		for _, owner := range rule.Owners {
+			exists, err := s.ownerExists(ctx, tx, owner)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, fmt.Errorf("%w: %s %q of pattern %q not found", ErrInvalidCodeOwners, owner.Type, owner.ID, rule.Pattern)
+			}
+		}
+	}
+
+	if err := s.repoOwners.ReplaceRules(ctx, tx, rules); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = []domain.OwnershipRule{}
+	}
+	return rules, nil
+}
+
+func (s *Service) GetCodeOwners(ctx context.Context) ([]domain.OwnershipRule, error) {
+	ctx, span := startSpan(ctx, "GetCodeOwners")
+	defer span.End()
+
+	return s.repoOwners.ListRules(ctx, s.db)
+}
+
+func (s *Service) ownerExists(ctx context.Context, db repository.Querier, owner domain.Owner) (bool, error) {
+	if owner.Type == domain.OwnerTeam {
+		return s.repoTeams.Exists(ctx, db, owner.ID)
+	}
+
+	user, err := s.repoUsers.GetByID(ctx, db, owner.ID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil, nil
+}
+
+// fileOwners — объединение владельцев всех изменённых файлов PR.
+type fileOwners struct {
+	userIDs []string
+	teams   []string
+}
+
+func (o fileOwners) empty() bool {
+	return len(o.userIDs) == 0 && len(o.teams) == 0
+}
+
+func (o fileOwners) includes(u domain.User) bool {
+	return slices.Contains(o.userIDs, u.ID) || slices.Contains(o.teams, u.TeamName)
+}
+
+func (s *Service) resolveFileOwners(ctx context.Context, db repository.Querier, files []string) (fileOwners, error) {
+	var owners fileOwners
+	if len(files) == 0 {
+		return owners, nil
+	}
+
+	rules, err := s.repoOwners.ListRules(ctx, db)
+	if err != nil {
+		return owners, err
+	}
+
+	for _, path := range files {
+		for _, owner := range codeowners.OwnersOf(rules, path) {
+			switch owner.Type {
+			case domain.OwnerUser:
+				if !slices.Contains(owners.userIDs, owner.ID) {
+					owners.userIDs = append(owners.userIDs, owner.ID)
+				}
+			case domain.OwnerTeam:
+				if !slices.Contains(owners.teams, owner.ID) {
+					owners.teams = append(owners.teams, owner.ID)
+				}
+			}
+		}
+	}
+
+	return owners, nil
+}
+
+// ownerCandidates возвращает активных владельцев кода, которых можно назначить ревьюерами.
+func (s *Service) ownerCandidates(ctx context.Context, db repository.Querier, owners fileOwners, excludeUserIDs []string) ([]domain.Candidate, error) {
+	candidates, err := s.repoUsers.GetActiveCandidatesByIDs(ctx, db, owners.userIDs, excludeUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range owners.teams {
+		members, err := s.repoUsers.GetActiveCandidates(ctx, db, team, excludeUserIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if !slices.ContainsFunc(candidates, func(c domain.Candidate) bool { return c.ID == m.ID }) {
+				candidates = append(candidates, m)
+			}
+		}
+	}
+
+	return candidates, nil
+}
+
+// logOwnerMissing предупреждает, что PR остался без владельца изменённых файлов среди ревьюеров.
+func logOwnerMissing(ctx context.Context, prID string, owners fileOwners) {
+	logging.FromContext(ctx).Warn("No active code owner available, reviewers selected without owners",
+		zap.String("pull_request_id", prID),
+		zap.Strings("owner_users", owners.userIDs),
+		zap.Strings("owner_teams", owners.teams),
+	)
+}
+
+func normalizePaths(paths []string) []string {
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "/")
+		if p != "" && !slices.Contains(result, p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+const maxPRNameLength = 255
+
+// SetExternalLogin привязывает логин внешней системы к пользователю.
+func (s *Service) SetExternalLogin(ctx context.Context, account domain.ExternalAccount) (*domain.ExternalAccount, error) {
+	ctx, span := startSpan(ctx, "SetExternalLogin")
+	defer span.End()
+
+	if !account.Provider.Valid() {
+		return nil, fmt.Errorf("%w: unknown provider %q", ErrInvalidExternalAccount, account.Provider)
+	}
+	account.Login = strings.ToLower(strings.TrimSpace(account.Login))
+	if account.Login == "" {
+		return nil, fmt.Errorf("%w: login is required", ErrInvalidExternalAccount)
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := s.repoUsers.GetByID(ctx, tx, account.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.repoUsers.SetExternalLogin(ctx, tx, account); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// ApplyExternalPREvent выполняет действие над PR, о котором сообщила внешняя
+// система. Повторная доставка события открытия не создаёт PR заново.
+func (s *Service) ApplyExternalPREvent(ctx context.Context, ev domain.ExternalPREvent) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "ApplyExternalPREvent")
+	defer span.End()
+
+	logging.FromContext(ctx).Info("Applying external pull request event",
+		zap.String("provider", string(ev.Provider)),
+		zap.String("action", string(ev.Action)),
+		zap.String("pull_request_id", ev.PullRequestID),
+	)
+
+	switch ev.Action {
+	case domain.ExternalPROpened:
+		return s.createExternalPR(ctx, ev)
+	case domain.ExternalPRMerged:
+		// PR уже слит во внешней системе, поэтому политика не блокирует слияние,
+		// а невыполненные условия записываются в журнал
+		override := &domain.MergeOverride{
+			ForcedBy: string(ev.Provider) + ":" + strings.ToLower(ev.SenderLogin),
+			Reason:   "merged in " + string(ev.Provider),
+		}
+		return s.MergePR(ctx, ev.PullRequestID, override)
+	case domain.ExternalPRClosed:
+		return s.ClosePR(ctx, ev.PullRequestID)
+	case domain.ExternalPRReopened:
+		return s.ReopenPR(ctx, ev.PullRequestID)
+	case domain.ExternalPRReady:
+		return s.MarkReady(ctx, ev.PullRequestID)
+	default:
+		return nil, fmt.Errorf("unsupported external action %q", ev.Action)
+	}
+}
+
+func (s *Service) createExternalPR(ctx context.Context, ev domain.ExternalPREvent) (*domain.PullRequest, error) {
+	author, err := s.resolveExternalAuthor(ctx, ev)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := domain.PullRequest{
+		ID:       ev.PullRequestID,
+		Name:     truncateRunes(ev.Title, maxPRNameLength),
+		AuthorID: author.ID,
+		Labels:   externalLabels(ev.Labels),
+	}
+	if ev.Draft {
+		pr.Status = domain.PRStatusDraft
+	}
+
+	created, err := s.CreatePR(ctx, pr)
+	if err == ErrPRExists {
+		return s.repoPR.GetByID(ctx, s.db, ev.PullRequestID)
+	}
+	return created, err
+}
+
+// resolveExternalAuthor ищет автора события сначала по привязке его id
+// во внешней системе, затем по логину.
+func (s *Service) resolveExternalAuthor(ctx context.Context, ev domain.ExternalPREvent) (*domain.User, error) {
+	if ev.AuthorID != "" {
+		user, err := s.repoUsers.GetByExternalLogin(ctx, s.db, ev.Provider, ev.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			return user, nil
+		}
+	}
+	if ev.AuthorLogin == "" {
+		return nil, ErrAuthorNotFound
+	}
+	return s.resolveExternalUser(ctx, ev.Provider, ev.AuthorLogin)
+}
+
+// resolveExternalUser ищет пользователя по привязанному логину, а если
+// привязки нет — пользователя, чей id совпадает с логином.
+func (s *Service) resolveExternalUser(ctx context.Context, provider domain.Provider, login string) (*domain.User, error) {
+	login = strings.ToLower(login)
+	user, err := s.repoUsers.GetByExternalLogin(ctx, s.db, provider, login)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	user, err = s.repoUsers.GetByID(ctx, s.db, login)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrAuthorNotFound
+	}
+	return user, nil
+}
+
+// externalLabels приводит метки внешней системы к формату тегов:
+// пробелы заменяются на "-", непригодные метки отбрасываются.
+func externalLabels(labels []string) []string {
+	result := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.Join(strings.FieldsFunc(label, unicode.IsSpace), "-")
+		if label == "" || len(label) > 64 || strings.Contains(label, ",") {
+			continue
+		}
+		result = append(result, label)
+	}
+	return result
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return s
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/metrics"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// transitions — допустимые переходы статусов PR. MERGED — конечный статус.
+var transitions = map[domain.PRStatus][]domain.PRStatus{
+	domain.PRStatusDraft:  {domain.PRStatusOpen, domain.PRStatusClosed},
+	domain.PRStatusOpen:   {domain.PRStatusMerged, domain.PRStatusClosed},
+	domain.PRStatusClosed: {domain.PRStatusOpen},
+}
+
+func checkTransition(from, to domain.PRStatus) error {
+	if slices.Contains(transitions[from], to) {
+		return nil
+	}
+	return ErrInvalidTransition
+}
+
+// ClosePR закрывает PR без слияния.
+func (s *Service) ClosePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "ClosePR")
+	defer span.End()
+
+	return s.changeStatus(ctx, prID, domain.PRStatusClosed)
+}
+
+// ReopenPR снова открывает закрытый PR. Если ревьюеров у него нет
+// (например, он был закрыт черновиком), они назначаются заново.
+func (s *Service) ReopenPR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "ReopenPR")
+	defer span.End()
+
+	return s.changeStatus(ctx, prID, domain.PRStatusOpen, domain.PRStatusClosed)
+}
+
+// MarkReady переводит черновик в статус OPEN и назначает ревьюеров.
+func (s *Service) MarkReady(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "MarkReady")
+	defer span.End()
+
+	return s.changeStatus(ctx, prID, domain.PRStatusOpen, domain.PRStatusDraft)
+}
+
+// changeStatus переводит PR в статус to. Если задан from, исходный статус
+// обязан быть одним из них — так reopen и ready не путаются между собой.
+func (s *Service) changeStatus(ctx context.Context, prID string, to domain.PRStatus, from ...domain.PRStatus) (*domain.PullRequest, error) {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	selectors := s.selectors.Begin()
+
+	pr, err := s.repoPR.GetByID(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, ErrPRNotFound
+	}
+
+	if len(from) > 0 && !slices.Contains(from, pr.Status) {
+		return nil, ErrInvalidTransition
+	}
+	if err := checkTransition(pr.Status, to); err != nil {
+		return nil, err
+	}
+
+	if to == domain.PRStatusOpen && len(pr.Reviewers) == 0 {
+		author, err := s.repoUsers.GetByID(ctx, tx, pr.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if author == nil {
+			return nil, ErrAuthorNotFound
+		}
+
+		if err := s.assignReviewers(ctx, tx, selectors, pr, author); err != nil {
+			return nil, err
+		}
+		for _, r := range pr.Reviewers {
+			if err := s.repoPR.AddReviewer(ctx, tx, prID, r.ID); err != nil {
+				return nil, err
+			}
+		}
+	} else if to == domain.PRStatusOpen {
+		// Пока PR был закрыт, SLA не шёл: отсчёт начинается заново
+		if err := s.repoPR.RestartAssignments(ctx, tx, prID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repoPR.SetStatus(ctx, tx, prID, to); err != nil {
+		return nil, err
+	}
+
+	previous := pr.Status
+	eventType := domain.EventPRReopened
+	switch {
+	case to == domain.PRStatusClosed:
+		eventType = domain.EventPRClosed
+	case previous == domain.PRStatusDraft:
+		eventType = domain.EventPRReady
+	}
+
+	pr.Status = to
+	if to == domain.PRStatusClosed {
+		now := time.Now()
+		pr.ClosedAt = &now
+	} else {
+		pr.ClosedAt = nil
+	}
+
+	// Как и при создании, событие несёт назначенных ревьюеров и пишется в той же транзакции
+	if err := s.publish(ctx, tx, eventType, pullRequestEvent(pr)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	selectors.Commit()
+	metrics.PRTransitions.WithLabelValues(string(previous), string(to)).Inc()
+	logging.FromContext(ctx).Info("Pull request status changed",
+		zap.String("pull_request_id", prID),
+		zap.String("from", string(previous)),
+		zap.String("to", string(to)),
+		zap.Strings("reviewers", reviewerIDs(pr.Reviewers)),
+	)
+
+	return pr, nil
+}
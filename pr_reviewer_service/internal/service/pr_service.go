@@ -2,19 +2,30 @@ package service
 
 import (
 	"context"
-	"math/rand"
+	"fmt"
 	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/metrics"
+	"pr-reviewer/internal/repository"
 	"time"
+
+	"go.uber.org/zap"
 )
 
-func (s *Service) CreatePR(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error) {
-	tx, err := s.db.BeginTx(ctx, nil)
+// CreatePR создаёт PR из pr.ID, pr.Name, pr.AuthorID, pr.ChangedFiles и pr.Labels
+// и назначает ему ревьюеров. Если pr.Status равен DRAFT, PR создаётся черновиком
+// без ревьюеров; остальные поля pr заполняются сервисом.
+func (s *Service) CreatePR(ctx context.Context, pr domain.PullRequest) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "CreatePR")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	exists, err := s.repoPR.Exists(ctx, tx, prID)
+	exists, err := s.repoPR.Exists(ctx, tx, pr.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -22,7 +33,7 @@ func (s *Service) CreatePR(ctx context.Context, prID, prName, authorID string) (
 		return nil, ErrPRExists
 	}
 
-	author, err := s.repoUsers.GetByID(ctx, tx, authorID)
+	author, err := s.repoUsers.GetByID(ctx, tx, pr.AuthorID)
 	if err != nil {
 		return nil, err
 	}
@@ -30,49 +41,157 @@ func (s *Service) CreatePR(ctx context.Context, prID, prName, authorID string) (
 		return nil, ErrAuthorNotFound
 	}
 
-	candidates, err := s.repoUsers.GetActiveCandidates(ctx, tx, author.TeamName, []string{authorID})
+	pr.Labels, err = normalizeTags(pr.Labels)
 	if err != nil {
 		return nil, err
 	}
-
-	reviewers := selectRandomReviewers(candidates, 2)
-
-	pr := domain.PullRequest{
-		ID:        prID,
-		Name:      prName,
-		AuthorID:  authorID,
-		Status:    domain.PRStatusOpen,
-		Reviewers: reviewers,
+	pr.ChangedFiles = normalizePaths(pr.ChangedFiles)
+	pr.Reviewers = []domain.User{}
+
+	// Ревьюеры черновику назначаются, когда он становится готов к ревью
+	selectors := s.selectors.Begin()
+	if pr.Status != domain.PRStatusDraft {
+		pr.Status = domain.PRStatusOpen
+		if err := s.assignReviewers(ctx, tx, selectors, &pr, author); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.repoPR.Create(ctx, tx, pr); err != nil {
 		return nil, err
 	}
 
+	if err := s.publish(ctx, tx, domain.EventPRCreated, pullRequestEvent(&pr)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
+	selectors.Commit()
+	metrics.PRsCreated.Inc()
+	logging.FromContext(ctx).Info("Pull request created",
+		zap.String("pull_request_id", pr.ID),
+		zap.String("status", string(pr.Status)),
+		zap.Strings("reviewers", reviewerIDs(pr.Reviewers)),
+	)
 
 	return &pr, nil
 }
 
-func selectRandomReviewers(candidates []domain.User, limit int) []domain.User {
-	if len(candidates) == 0 {
-		return []domain.User{}
+// assignReviewers подбирает ревьюеров для pr по настройкам команды автора
+// и записывает их в pr.Reviewers и pr.FallbackReviewerIDs, не сохраняя в БД.
+func (s *Service) assignReviewers(ctx context.Context, db repository.Querier, selectors *TeamSelectors, pr *domain.PullRequest, author *domain.User) error {
+	settings, err := s.teamSettings(ctx, db, author.TeamName)
+	if err != nil {
+		return err
 	}
 
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
+	owners, err := s.resolveFileOwners(ctx, db, pr.ChangedFiles)
+	if err != nil {
+		return err
+	}
 
-	if len(candidates) > limit {
-		return candidates[:limit]
+	exclude := []string{pr.AuthorID}
+	reviewers := []domain.User{}
+	// Если у изменённых файлов есть владельцы, хотя бы один из них должен попасть
+	// в ревьюеры. Когда назначить некого (владелец — сам автор, неактивен или
+	// в отсутствии), PR отклоняется, а с включённым OwnerFallback ревьюеры
+	// выбираются как обычно и PR помечается OwnerMissing.
+	if !owners.empty() {
+		ownerCandidates, err := s.ownerCandidates(ctx, db, owners, exclude)
+		if err != nil {
+			return err
+		}
+		selector := selectors.ForStrategy(Strategy(settings.Strategy))
+		selected, _ := selectByExpertise(selector, author.TeamName, ownerCandidates, pr.Labels, 1)
+		if len(selected) > 0 {
+			reviewers = append(reviewers, selected[0])
+			exclude = append(exclude, selected[0].ID)
+		} else {
+			if !settings.OwnerFallback {
+				return ErrNoOwnerAvailable
+			}
+			pr.OwnerMissing = true
+			logOwnerMissing(ctx, pr.ID, owners)
+		}
+	}
+
+	rest, fallbackIDs, err := s.pickReviewers(ctx, db, selectors, settings, exclude, pr.Labels, settings.ReviewerCount-len(reviewers))
+	if err != nil {
+		return err
 	}
-	return candidates
+	reviewers = append(reviewers, rest...)
+	if len(reviewers) < settings.MinReviewers {
+		return ErrNotEnoughReviewers
+	}
+
+	pr.Reviewers = reviewers
+	pr.FallbackReviewerIDs = fallbackIDs
+	return nil
 }
 
-func (s *Service) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	tx, err := s.db.BeginTx(ctx, nil)
+// pickReviewers выбирает до count ревьюеров из команды settings.TeamName,
+// а при нехватке добирает их из резервных команд в порядке приоритета.
+// Вторым значением возвращаются id ревьюеров из резервных команд.
+func (s *Service) pickReviewers(
+	ctx context.Context,
+	db repository.Querier,
+	selectors *TeamSelectors,
+	settings domain.TeamSettings,
+	excludeUserIDs []string,
+	labels []string,
+	count int,
+) ([]domain.User, []string, error) {
+	exclude := append([]string{}, excludeUserIDs...)
+	reviewers := []domain.User{}
+	fallbackIDs := []string{}
+
+	teams := append([]string{settings.TeamName}, settings.FallbackTeams...)
+	for i, team := range teams {
+		if len(reviewers) >= count {
+			break
+		}
+
+		strategy := Strategy(settings.Strategy)
+		if i > 0 {
+			teamSettings, err := s.teamSettings(ctx, db, team)
+			if err != nil {
+				return nil, nil, err
+			}
+			strategy = Strategy(teamSettings.Strategy)
+		}
+
+		candidates, err := s.repoUsers.GetActiveCandidates(ctx, db, team, exclude)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		selector := selectors.ForStrategy(strategy)
+		selected, top := selectByExpertise(selector, team, candidates, labels, count-len(reviewers))
+		if i == 0 {
+			commitSelection(selector, team, top)
+		}
+		for _, u := range selected {
+			reviewers = append(reviewers, u)
+			exclude = append(exclude, u.ID)
+			if i > 0 {
+				fallbackIDs = append(fallbackIDs, u.ID)
+			}
+		}
+	}
+
+	return reviewers, fallbackIDs, nil
+}
+
+// MergePR сливает PR, если выполнена политика слияния команды автора.
+// Непустой override позволяет слить PR в обход политики; такое слияние
+// записывается в журнал вместе с невыполненными условиями.
+func (s *Service) MergePR(ctx context.Context, prID string, override *domain.MergeOverride) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "MergePR")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +208,24 @@ func (s *Service) MergePR(ctx context.Context, prID string) (*domain.PullRequest
 	if pr.Status == domain.PRStatusMerged {
 		return pr, nil
 	}
+	if err := checkTransition(pr.Status, domain.PRStatusMerged); err != nil {
+		return nil, err
+	}
+
+	violation, err := s.mergePolicyViolation(ctx, tx, pr)
+	if err != nil {
+		return nil, err
+	}
+	if violation != "" {
+		if override == nil {
+			return nil, fmt.Errorf("%w: %s", ErrMergePolicyUnmet, violation)
+		}
+		override.PullRequestID = prID
+		override.UnmetPolicy = violation
+		if err := s.repoPR.AddMergeOverride(ctx, tx, *override); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repoPR.SetStatus(ctx, tx, prID, domain.PRStatusMerged); err != nil {
 		return nil, err
@@ -98,20 +235,69 @@ func (s *Service) MergePR(ctx context.Context, prID string) (*domain.PullRequest
 	now := time.Now()
 	pr.MergedAt = &now
 
+	if err := s.publish(ctx, tx, domain.EventPRMerged, pullRequestEvent(pr)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
+	metrics.PRsMerged.Inc()
+	if violation != "" {
+		logging.FromContext(ctx).Warn("Pull request merged despite unmet merge policy",
+			zap.String("pull_request_id", prID),
+			zap.String("unmet_policy", violation),
+			zap.String("forced_by", override.ForcedBy),
+		)
+	} else {
+		logging.FromContext(ctx).Info("Pull request merged", zap.String("pull_request_id", prID))
+	}
 
 	return pr, nil
 }
 
+// ReassignReviewer заменяет ревьюера oldUserID; пустой oldUserID — отказ
+// от ревью пользователя, от имени которого выполняется вызов.
 func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, *domain.User, error) {
-	tx, err := s.db.BeginTx(ctx, nil)
+	ctx, span := startSpan(ctx, "ReassignReviewer")
+	defer span.End()
+
+	if oldUserID == "" {
+		actor, ok := ActingUser(ctx)
+		if !ok {
+			return nil, nil, ErrNoActingUser
+		}
+		oldUserID = actor
+	}
+
+	tx, err := s.db.BeginTx(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer tx.Rollback()
 
+	selectors := s.selectors.Begin()
+	pr, newReviewer, err := s.reassignReviewer(ctx, tx, selectors, prID, oldUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	selectors.Commit()
+	metrics.ReviewersReassigned.Inc()
+	logging.FromContext(ctx).Info("Reviewer reassigned",
+		zap.String("pull_request_id", prID),
+		zap.String("old_reviewer_id", oldUserID),
+		zap.String("new_reviewer_id", newReviewer.ID),
+	)
+
+	return pr, newReviewer, nil
+}
+
+// reassignReviewer заменяет ревьюера oldUserID в рамках уже открытой транзакции.
+func (s *Service) reassignReviewer(ctx context.Context, tx repository.Querier, selectors *TeamSelectors, prID, oldUserID string) (*domain.PullRequest, *domain.User, error) {
 	pr, err := s.repoPR.GetByID(ctx, tx, prID)
 	if err != nil {
 		return nil, nil, err
@@ -123,6 +309,9 @@ func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string)
 	if pr.Status == domain.PRStatusMerged {
 		return nil, nil, ErrPRMerged
 	}
+	if pr.Status != domain.PRStatusOpen {
+		return nil, nil, ErrPRNotOpen
+	}
 
 	isAssigned := false
 	currentReviewerIDs := []string{pr.AuthorID}
@@ -146,16 +335,57 @@ func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string)
 		return nil, nil, ErrUserNotFound
 	}
 
-	candidates, err := s.repoUsers.GetActiveCandidates(ctx, tx, oldReviewerUser.TeamName, currentReviewerIDs)
+	settings, err := s.teamSettings(ctx, tx, oldReviewerUser.TeamName)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if len(candidates) == 0 {
-		return nil, nil, ErrNoCandidate
+	owners, err := s.resolveFileOwners(ctx, tx, pr.ChangedFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Заменяемый ревьюер был единственным владельцем кода — замена тоже должна быть владельцем
+	lastOwner := owners.includes(*oldReviewerUser)
+	for _, r := range pr.Reviewers {
+		if r.ID != oldUserID && owners.includes(r) {
+			lastOwner = false
+		}
 	}
 
-	newReviewer := selectRandomReviewers(candidates, 1)[0]
+	var selected []domain.User
+	var fallbackIDs []string
+	if lastOwner {
+		ownerCandidates, err := s.ownerCandidates(ctx, tx, owners, currentReviewerIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		selector := selectors.ForStrategy(Strategy(settings.Strategy))
+		selected, _ = selectByExpertise(selector, oldReviewerUser.TeamName, ownerCandidates, pr.Labels, 1)
+		if len(selected) == 0 {
+			if !settings.OwnerFallback {
+				return nil, nil, ErrNoOwnerAvailable
+			}
+			pr.OwnerMissing = true
+			logOwnerMissing(ctx, prID, owners)
+		}
+	}
+	if len(selected) == 0 {
+		selected, fallbackIDs, err = s.pickReviewers(ctx, tx, selectors, settings, currentReviewerIDs, pr.Labels, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(selected) == 0 {
+		metrics.NoCandidate.Inc()
+		logging.FromContext(ctx).Debug("No replacement candidate",
+			zap.String("pull_request_id", prID),
+			zap.String("reviewer_id", oldUserID),
+		)
+		return nil, nil, ErrNoCandidate
+	}
+	newReviewer := selected[0]
+	pr.FallbackReviewerIDs = fallbackIDs
 
 	if err := s.repoPR.ReplaceReviewer(ctx, tx, prID, oldUserID, newReviewer.ID); err != nil {
 		return nil, nil, err
@@ -168,13 +398,33 @@ func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	event := domain.ReassignedEvent{PullRequestID: prID, OldReviewerID: oldUserID, NewReviewerID: newReviewer.ID}
+	if err := s.publish(ctx, tx, domain.EventPRReassigned, event); err != nil {
 		return nil, nil, err
 	}
 
 	return pr, &newReviewer, nil
 }
 
+// OpenReviewsByUser возвращает число открытых ревью у каждого ревьюера.
+func (s *Service) OpenReviewsByUser(ctx context.Context) (map[string]int, error) {
+	ctx, span := startSpan(ctx, "OpenReviewsByUser")
+	defer span.End()
+
+	return s.repoPR.CountOpenReviews(ctx, s.db)
+}
+
 func (s *Service) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
+	ctx, span := startSpan(ctx, "GetUserReviews")
+	defer span.End()
+
 	return s.repoPR.GetByReviewerID(ctx, s.db, userID)
 }
+
+func reviewerIDs(users []domain.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"strings"
+)
+
+// SubmitReview записывает решение назначенного ревьюера по открытому PR;
+// пустой reviewerID — решение пользователя, от имени которого выполняется вызов.
+func (s *Service) SubmitReview(ctx context.Context, prID, reviewerID string, decision domain.ReviewDecision) (*domain.Review, error) {
+	ctx, span := startSpan(ctx, "SubmitReview")
+	defer span.End()
+
+	if !decision.Valid() {
+		return nil, ErrInvalidDecision
+	}
+	if reviewerID == "" {
+		actor, ok := ActingUser(ctx)
+		if !ok {
+			return nil, ErrNoActingUser
+		}
+		reviewerID = actor
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pr, err := s.openPR(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if reviewerIndex(pr, reviewerID) < 0 {
+		return nil, ErrNotAssigned
+	}
+
+	review, err := s.repoPR.AddReview(ctx, tx, domain.Review{
+		PullRequestID: prID,
+		ReviewerID:    reviewerID,
+		Decision:      decision,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *Service) GetReviews(ctx context.Context, prID string) ([]domain.Review, error) {
+	ctx, span := startSpan(ctx, "GetReviews")
+	defer span.End()
+
+	exists, err := s.repoPR.Exists(ctx, s.db, prID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPRNotFound
+	}
+
+	return s.repoPR.GetReviews(ctx, s.db, prID)
+}
+
+// mergePolicyViolation возвращает описание невыполненных условий политики
+// слияния команды автора или пустую строку, если PR можно сливать.
+func (s *Service) mergePolicyViolation(ctx context.Context, db repository.Querier, pr *domain.PullRequest) (string, error) {
+	author, err := s.repoUsers.GetByID(ctx, db, pr.AuthorID)
+	if err != nil {
+		return "", err
+	}
+	if author == nil {
+		return "", ErrAuthorNotFound
+	}
+
+	settings, err := s.teamSettings(ctx, db, author.TeamName)
+	if err != nil {
+		return "", err
+	}
+	if settings.RequiredApprovals == 0 && !settings.BlockOnChangesRequested {
+		return "", nil
+	}
+
+	reviews, err := s.repoPR.GetReviews(ctx, db, pr.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// Учитываются только текущие ревьюеры и их последнее решение;
+	// комментарий не отменяет ранее поставленный approve или request changes.
+	states := make(map[string]domain.ReviewDecision)
+	for _, rv := range reviews {
+		if reviewerIndex(pr, rv.ReviewerID) < 0 || rv.Decision == domain.ReviewCommented {
+			continue
+		}
+		states[rv.ReviewerID] = rv.Decision
+	}
+
+	approvals := 0
+	var changesRequested []string
+	for _, r := range pr.Reviewers {
+		switch states[r.ID] {
+		case domain.ReviewApproved:
+			approvals++
+		case domain.ReviewChangesRequested:
+			changesRequested = append(changesRequested, r.ID)
+		}
+	}
+
+	var violations []string
+	if approvals < settings.RequiredApprovals {
+		violations = append(violations, fmt.Sprintf("%d of %d required approvals", approvals, settings.RequiredApprovals))
+	}
+	if settings.BlockOnChangesRequested && len(changesRequested) > 0 {
+		violations = append(violations, "changes requested by "+strings.Join(changesRequested, ", "))
+	}
+
+	return strings.Join(violations, "; "), nil
+}
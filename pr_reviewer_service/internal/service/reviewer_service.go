@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/metrics"
+	"pr-reviewer/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// AddReviewer назначает PR дополнительного ревьюера, выбранного вручную.
+func (s *Service) AddReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "AddReviewer")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pr, err := s.openPR(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.validateNewReviewer(ctx, tx, pr, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repoPR.AddReviewer(ctx, tx, prID, userID); err != nil {
+		return nil, err
+	}
+	pr.Reviewers = append(pr.Reviewers, *user)
+
+	event := domain.ReviewerEvent{PullRequestID: prID, ReviewerID: userID}
+	if err := s.publish(ctx, tx, domain.EventReviewerAdded, event); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	metrics.ReviewersAdded.Inc()
+	logging.FromContext(ctx).Info("Reviewer added",
+		zap.String("pull_request_id", prID),
+		zap.String("reviewer_id", userID),
+	)
+
+	return pr, nil
+}
+
+// RemoveReviewer снимает ревьюера с PR, не опуская их число ниже
+// min_reviewers команды автора.
+func (s *Service) RemoveReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "RemoveReviewer")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pr, err := s.openPR(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := reviewerIndex(pr, userID)
+	if idx < 0 {
+		return nil, ErrNotAssigned
+	}
+
+	author, err := s.repoUsers.GetByID(ctx, tx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	if author == nil {
+		return nil, ErrAuthorNotFound
+	}
+
+	settings, err := s.teamSettings(ctx, tx, author.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(pr.Reviewers)-1 < settings.MinReviewers {
+		return nil, ErrNotEnoughReviewers
+	}
+
+	if err := s.repoPR.RemoveReviewer(ctx, tx, prID, userID); err != nil {
+		return nil, err
+	}
+	pr.Reviewers = append(pr.Reviewers[:idx], pr.Reviewers[idx+1:]...)
+
+	event := domain.ReviewerEvent{PullRequestID: prID, ReviewerID: userID}
+	if err := s.publish(ctx, tx, domain.EventReviewerRemoved, event); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	metrics.ReviewersRemoved.Inc()
+	logging.FromContext(ctx).Info("Reviewer removed",
+		zap.String("pull_request_id", prID),
+		zap.String("reviewer_id", userID),
+	)
+
+	return pr, nil
+}
+
+// ReassignReviewerTo заменяет ревьюера oldUserID на явно указанного newUserID.
+func (s *Service) ReassignReviewerTo(ctx context.Context, prID, oldUserID, newUserID string) (*domain.PullRequest, error) {
+	ctx, span := startSpan(ctx, "ReassignReviewerTo")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pr, err := s.openPR(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := reviewerIndex(pr, oldUserID)
+	if idx < 0 {
+		return nil, ErrNotAssigned
+	}
+
+	user, err := s.validateNewReviewer(ctx, tx, pr, newUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repoPR.ReplaceReviewer(ctx, tx, prID, oldUserID, newUserID); err != nil {
+		return nil, err
+	}
+	pr.Reviewers[idx] = *user
+
+	event := domain.ReassignedEvent{PullRequestID: prID, OldReviewerID: oldUserID, NewReviewerID: newUserID}
+	if err := s.publish(ctx, tx, domain.EventPRReassigned, event); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	metrics.ReviewersReassigned.Inc()
+	logging.FromContext(ctx).Info("Reviewer reassigned explicitly",
+		zap.String("pull_request_id", prID),
+		zap.String("old_reviewer_id", oldUserID),
+		zap.String("new_reviewer_id", newUserID),
+	)
+
+	return pr, nil
+}
+
+// openPR загружает PR, с ревьюерами которого ещё можно работать.
+func (s *Service) openPR(ctx context.Context, db repository.Querier, prID string) (*domain.PullRequest, error) {
+	pr, err := s.repoPR.GetByID(ctx, db, prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, ErrPRNotFound
+	}
+	if pr.Status == domain.PRStatusMerged {
+		return nil, ErrPRMerged
+	}
+	if pr.Status != domain.PRStatusOpen {
+		return nil, ErrPRNotOpen
+	}
+	return pr, nil
+}
+
+// validateNewReviewer проверяет, что userID можно назначить ревьюером pr:
+// пользователь существует, активен и не в отсутствии, не автор и ещё не назначен.
+func (s *Service) validateNewReviewer(ctx context.Context, db repository.Querier, pr *domain.PullRequest, userID string) (*domain.User, error) {
+	user, err := s.repoUsers.GetByID(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if userID == pr.AuthorID {
+		return nil, ErrReviewerIsAuthor
+	}
+	if reviewerIndex(pr, userID) >= 0 {
+		return nil, ErrAlreadyAssigned
+	}
+
+	available, err := s.repoUsers.GetActiveCandidatesByIDs(ctx, db, []string{userID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(available) == 0 {
+		return nil, ErrReviewerInactive
+	}
+
+	return user, nil
+}
+
+func reviewerIndex(pr *domain.PullRequest, userID string) int {
+	for i, r := range pr.Reviewers {
+		if r.ID == userID {
+			return i
+		}
+	}
+	return -1
+}
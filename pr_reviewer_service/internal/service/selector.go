@@ -0,0 +1,302 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+
+	"pr-reviewer/internal/domain"
+)
+
+type Strategy string
+
+const (
+	StrategyRandom      Strategy = "random"
+	StrategyRoundRobin  Strategy = "round_robin"
+	StrategyLeastLoaded Strategy = "least_loaded"
+)
+
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(s) {
+	case StrategyRandom, StrategyRoundRobin, StrategyLeastLoaded:
+		return Strategy(s), nil
+	default:
+		return "", fmt.Errorf("unknown reviewer strategy %q", s)
+	}
+}
+
+// ReviewerSelector выбирает до limit ревьюеров из кандидатов одной команды.
+// Реализация не должна изменять переданный слайс.
+type ReviewerSelector interface {
+	Select(teamName string, candidates []domain.Candidate, limit int) []domain.User
+}
+
+type randomSelector struct{}
+
+func (randomSelector) Select(_ string, candidates []domain.Candidate, limit int) []domain.User {
+	users := candidateUsers(candidates)
+	rand.Shuffle(len(users), func(i, j int) {
+		users[i], users[j] = users[j], users[i]
+	})
+	return truncate(users, limit)
+}
+
+// committingSelector — стратегия с состоянием. Её Select только предлагает
+// ревьюеров, а состояние меняет Commit, который вызывается лишь для ревьюеров,
+// окончательно назначенных из команды: уровни экспертизы ниже верхнего,
+// владельцы кода и резервные команды позицию не сдвигают.
+//
+// Внутри транзакции работает копия из Fork: последующие выборы в той же
+// транзакции видят её состояние, а в исходную стратегию оно переносится
+// через Merge только после фиксации.
+type committingSelector interface {
+	ReviewerSelector
+	Commit(teamName string, assigned []domain.User)
+	Fork() committingSelector
+	Merge()
+}
+
+// roundRobinSelector обходит кандидатов команды по порядку id, начиная
+// с первого после последнего назначенного в этой команде.
+type roundRobinSelector struct {
+	mu   sync.Mutex
+	last map[string]string
+	// parent — исходная стратегия копии, созданной Fork
+	parent *roundRobinSelector
+}
+
+func newRoundRobinSelector() *roundRobinSelector {
+	return &roundRobinSelector{last: make(map[string]string)}
+}
+
+func (s *roundRobinSelector) Select(teamName string, candidates []domain.Candidate, limit int) []domain.User {
+	users := candidateUsers(candidates)
+	if len(users) == 0 || limit <= 0 {
+		return []domain.User{}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	if limit > len(users) {
+		limit = len(users)
+	}
+
+	last := s.lastOf(teamName)
+	start := sort.Search(len(users), func(i int) bool { return users[i].ID > last })
+
+	result := make([]domain.User, 0, limit)
+	for i := 0; i < limit; i++ {
+		result = append(result, users[(start+i)%len(users)])
+	}
+	return result
+}
+
+// lastOf возвращает последнего назначенного в команде; копия без своих
+// назначений в команде берёт его из исходной стратегии.
+func (s *roundRobinSelector) lastOf(teamName string) string {
+	s.mu.Lock()
+	last, ok := s.last[teamName]
+	s.mu.Unlock()
+	if !ok && s.parent != nil {
+		return s.parent.lastOf(teamName)
+	}
+	return last
+}
+
+func (s *roundRobinSelector) Commit(teamName string, assigned []domain.User) {
+	if len(assigned) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.last[teamName] = assigned[len(assigned)-1].ID
+	s.mu.Unlock()
+}
+
+func (s *roundRobinSelector) Fork() committingSelector {
+	return &roundRobinSelector{last: make(map[string]string), parent: s}
+}
+
+func (s *roundRobinSelector) Merge() {
+	if s.parent == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for team, last := range s.last {
+		s.parent.Commit(team, []domain.User{{ID: last}})
+	}
+}
+
+// leastLoadedSelector отдаёт предпочтение кандидатам с наименьшим
+// числом открытых ревью; при равной нагрузке выбор случайный.
+type leastLoadedSelector struct{}
+
+func (leastLoadedSelector) Select(_ string, candidates []domain.Candidate, limit int) []domain.User {
+	sorted := make([]domain.Candidate, len(candidates))
+	copy(sorted, candidates)
+	rand.Shuffle(len(sorted), func(i, j int) {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	})
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OpenReviews < sorted[j].OpenReviews
+	})
+	return truncate(candidateUsers(sorted), limit)
+}
+
+func newSelector(strategy Strategy) ReviewerSelector {
+	switch strategy {
+	case StrategyRoundRobin:
+		return newRoundRobinSelector()
+	case StrategyLeastLoaded:
+		return leastLoadedSelector{}
+	default:
+		return randomSelector{}
+	}
+}
+
+// TeamSelectors хранит глобальную стратегию выбора ревьюеров
+// и переопределения для отдельных команд.
+type TeamSelectors struct {
+	defaultStrategy Strategy
+	teamStrategies  map[string]Strategy
+	selectors       map[Strategy]ReviewerSelector
+}
+
+func NewTeamSelectors(defaultStrategy string, teamStrategies map[string]string) (*TeamSelectors, error) {
+	def, err := ParseStrategy(defaultStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]Strategy, len(teamStrategies))
+	for team, name := range teamStrategies {
+		st, err := ParseStrategy(name)
+		if err != nil {
+			return nil, fmt.Errorf("team %s: %w", team, err)
+		}
+		teams[team] = st
+	}
+
+	return &TeamSelectors{
+		defaultStrategy: def,
+		teamStrategies:  teams,
+		selectors: map[Strategy]ReviewerSelector{
+			StrategyRandom:      newSelector(StrategyRandom),
+			StrategyRoundRobin:  newSelector(StrategyRoundRobin),
+			StrategyLeastLoaded: newSelector(StrategyLeastLoaded),
+		},
+	}, nil
+}
+
+// Begin возвращает селекторы для одной транзакции: стратегии с состоянием
+// в них заменены копиями. Commit переносит выбор в общее состояние и
+// вызывается после фиксации транзакции; при откате копии просто отбрасываются.
+func (t *TeamSelectors) Begin() *TeamSelectors {
+	selectors := make(map[Strategy]ReviewerSelector, len(t.selectors))
+	for strategy, sel := range t.selectors {
+		if c, ok := sel.(committingSelector); ok {
+			sel = c.Fork()
+		}
+		selectors[strategy] = sel
+	}
+	return &TeamSelectors{
+		defaultStrategy: t.defaultStrategy,
+		teamStrategies:  t.teamStrategies,
+		selectors:       selectors,
+	}
+}
+
+func (t *TeamSelectors) Commit() {
+	for _, sel := range t.selectors {
+		if c, ok := sel.(committingSelector); ok {
+			c.Merge()
+		}
+	}
+}
+
+func (t *TeamSelectors) StrategyFor(teamName string) Strategy {
+	if st, ok := t.teamStrategies[teamName]; ok {
+		return st
+	}
+	return t.defaultStrategy
+}
+
+func (t *TeamSelectors) For(teamName string) ReviewerSelector {
+	return t.ForStrategy(t.StrategyFor(teamName))
+}
+
+func (t *TeamSelectors) ForStrategy(strategy Strategy) ReviewerSelector {
+	if sel, ok := t.selectors[strategy]; ok {
+		return sel
+	}
+	return t.selectors[t.defaultStrategy]
+}
+
+// selectByExpertise сначала выбирает среди кандидатов с наибольшим числом
+// тегов, совпадающих с метками PR, и переходит к следующему уровню, только
+// если кандидатов не хватило. Внутри уровня выбор делает стратегия команды.
+// Вторым значением возвращаются ревьюеры верхнего уровня: только их выбор
+// сдвигает состояние стратегии.
+func selectByExpertise(sel ReviewerSelector, teamName string, candidates []domain.Candidate, labels []string, limit int) ([]domain.User, []domain.User) {
+	if len(labels) == 0 {
+		selected := sel.Select(teamName, candidates, limit)
+		return selected, selected
+	}
+
+	tiers := make(map[int][]domain.Candidate)
+	for _, c := range candidates {
+		score := 0
+		for _, tag := range c.Tags {
+			if slices.Contains(labels, tag) {
+				score++
+			}
+		}
+		tiers[score] = append(tiers[score], c)
+	}
+
+	scores := make([]int, 0, len(tiers))
+	for score := range tiers {
+		scores = append(scores, score)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+
+	result := []domain.User{}
+	var top []domain.User
+	for _, score := range scores {
+		if len(result) >= limit {
+			break
+		}
+		selected := sel.Select(teamName, tiers[score], limit-len(result))
+		if top == nil {
+			top = selected
+		}
+		result = append(result, selected...)
+	}
+	return result, top
+}
+
+// commitSelection сообщает стратегии с состоянием об окончательном выборе.
+func commitSelection(sel ReviewerSelector, teamName string, assigned []domain.User) {
+	if c, ok := sel.(committingSelector); ok {
+		c.Commit(teamName, assigned)
+	}
+}
+
+func candidateUsers(candidates []domain.Candidate) []domain.User {
+	users := make([]domain.User, len(candidates))
+	for i, c := range candidates {
+		users[i] = c.User
+	}
+	return users
+}
+
+func truncate(users []domain.User, limit int) []domain.User {
+	if limit < 0 {
+		limit = 0
+	}
+	if len(users) > limit {
+		return users[:limit]
+	}
+	return users
+}
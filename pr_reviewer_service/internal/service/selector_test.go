@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository/memory"
+)
+
+func candidates(ids ...string) []domain.Candidate {
+	result := make([]domain.Candidate, len(ids))
+	for i, id := range ids {
+		result[i] = domain.Candidate{User: domain.User{ID: id, IsActive: true}}
+	}
+	return result
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func newTestSelectors(t *testing.T) *TeamSelectors {
+	t.Helper()
+	selectors, err := NewTeamSelectors(string(StrategyRoundRobin), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return selectors
+}
+
+// selectInTx выбирает ревьюеров так же, как pickReviewers, и фиксирует
+// выбор в общем состоянии, только если commit истинен.
+func selectInTx(selectors *TeamSelectors, team string, cands []domain.Candidate, limit int, commit bool) []string {
+	tx := selectors.Begin()
+	sel := tx.ForStrategy(StrategyRoundRobin)
+	selected := sel.Select(team, cands, limit)
+	commitSelection(sel, team, selected)
+	if commit {
+		tx.Commit()
+	}
+	return userIDs(selected)
+}
+
+func TestRoundRobinRotation(t *testing.T) {
+	selectors := newTestSelectors(t)
+	// Порядок обхода не зависит от порядка кандидатов
+	cands := candidates("u3", "u1", "u4", "u2")
+
+	want := [][]string{{"u1", "u2"}, {"u3", "u4"}, {"u1", "u2"}}
+	for i, w := range want {
+		if got := selectInTx(selectors, "backend", cands, 2, true); !slices.Equal(got, w) {
+			t.Errorf("call %d = %v, want %v", i+1, got, w)
+		}
+	}
+
+	// Назначенный ранее ревьюер выпал из кандидатов — обход продолжается со следующего id
+	if got := selectInTx(selectors, "backend", candidates("u1", "u4"), 1, true); !slices.Equal(got, []string{"u4"}) {
+		t.Errorf("after u2 left = %v, want [u4]", got)
+	}
+}
+
+func TestRoundRobinRollbackDoesNotAdvance(t *testing.T) {
+	selectors := newTestSelectors(t)
+	cands := candidates("u1", "u2", "u3")
+
+	if got := selectInTx(selectors, "backend", cands, 1, false); !slices.Equal(got, []string{"u1"}) {
+		t.Fatalf("rolled back selection = %v, want [u1]", got)
+	}
+	if got := selectInTx(selectors, "backend", cands, 1, true); !slices.Equal(got, []string{"u1"}) {
+		t.Errorf("selection after rollback = %v, want [u1]", got)
+	}
+
+	// Внутри одной транзакции выбор продолжается с её позиции
+	tx := selectors.Begin()
+	sel := tx.ForStrategy(StrategyRoundRobin)
+	var got []string
+	for range 2 {
+		selected := sel.Select("backend", cands, 1)
+		commitSelection(sel, "backend", selected)
+		got = append(got, userIDs(selected)...)
+	}
+	if !slices.Equal(got, []string{"u2", "u3"}) {
+		t.Errorf("selections in one transaction = %v, want [u2 u3]", got)
+	}
+	if got := selectInTx(selectors, "backend", cands, 1, false); !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("selection before the transaction commits = %v, want [u2]", got)
+	}
+	tx.Commit()
+	if got := selectInTx(selectors, "backend", cands, 1, false); !slices.Equal(got, []string{"u1"}) {
+		t.Errorf("selection after commit = %v, want [u1]", got)
+	}
+}
+
+func TestRoundRobinTeamsAreIndependent(t *testing.T) {
+	selectors := newTestSelectors(t)
+	cands := candidates("u1", "u2", "u3")
+
+	selectInTx(selectors, "backend", cands, 2, true)
+	if got := selectInTx(selectors, "frontend", cands, 1, true); !slices.Equal(got, []string{"u1"}) {
+		t.Errorf("frontend = %v, want [u1]", got)
+	}
+	if got := selectInTx(selectors, "backend", cands, 1, true); !slices.Equal(got, []string{"u3"}) {
+		t.Errorf("backend = %v, want [u3]", got)
+	}
+	if got := selectInTx(selectors, "frontend", cands, 1, true); !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("frontend = %v, want [u2]", got)
+	}
+}
+
+// TestExpertiseCommitsTopTier проверяет, что позицию сдвигают только
+// ревьюеры верхнего уровня экспертизы, а добранные с нижних уровней — нет.
+func TestExpertiseCommitsTopTier(t *testing.T) {
+	selectors := newTestSelectors(t)
+	cands := candidates("u1", "u2", "u3", "u4")
+	cands[0].Tags = []string{"go"}
+
+	tx := selectors.Begin()
+	sel := tx.ForStrategy(StrategyRoundRobin)
+	selected, top := selectByExpertise(sel, "backend", cands, []string{"go"}, 3)
+	if got := userIDs(selected); !slices.Equal(got, []string{"u1", "u2", "u3"}) {
+		t.Errorf("selected = %v, want [u1 u2 u3]", got)
+	}
+	if got := userIDs(top); !slices.Equal(got, []string{"u1"}) {
+		t.Errorf("top tier = %v, want [u1]", got)
+	}
+	commitSelection(sel, "backend", top)
+	tx.Commit()
+
+	if got := selectInTx(selectors, "backend", cands, 1, true); !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("next selection = %v, want [u2]", got)
+	}
+}
+
+func TestRandomSelector(t *testing.T) {
+	cands := candidates("u1", "u2", "u3", "u4")
+	before := slices.Clone(cands)
+
+	seen := map[string]bool{}
+	for range 100 {
+		got := userIDs(randomSelector{}.Select("backend", cands, 2))
+		if len(got) != 2 || got[0] == got[1] {
+			t.Fatalf("selected = %v, want two different reviewers", got)
+		}
+		for _, id := range got {
+			seen[id] = true
+		}
+	}
+	if len(seen) != len(cands) {
+		t.Errorf("selected over 100 calls = %v, want every candidate", seen)
+	}
+	if !slices.EqualFunc(cands, before, func(a, b domain.Candidate) bool { return a.ID == b.ID }) {
+		t.Errorf("candidates were reordered: %v", cands)
+	}
+
+	var random ReviewerSelector = randomSelector{}
+	if got := random.Select("backend", cands, 10); len(got) != len(cands) {
+		t.Errorf("selected %d of %d candidates with a larger limit", len(got), len(cands))
+	}
+	if _, ok := random.(committingSelector); ok {
+		t.Error("random selector keeps state")
+	}
+}
+
+func TestLeastLoadedSelector(t *testing.T) {
+	cands := candidates("u1", "u2", "u3", "u4", "u5")
+	for i, load := range []int{3, 0, 1, 0, 5} {
+		cands[i].OpenReviews = load
+	}
+
+	// u2 и u4 без ревью идут первыми в случайном порядке, за ними u3
+	firsts := map[string]int{}
+	for range 200 {
+		got := userIDs(leastLoadedSelector{}.Select("backend", cands, 3))
+		if len(got) != 3 || got[2] != "u3" || !slices.Contains(got[:2], "u2") || !slices.Contains(got[:2], "u4") {
+			t.Fatalf("selected = %v, want u2 and u4 in any order, then u3", got)
+		}
+		firsts[got[0]]++
+	}
+	if firsts["u2"] == 0 || firsts["u4"] == 0 {
+		t.Errorf("first reviewer over 200 calls = %v, want ties broken randomly", firsts)
+	}
+
+	if got := userIDs(leastLoadedSelector{}.Select("backend", cands, 10)); !slices.Equal(got[2:], []string{"u3", "u1", "u5"}) {
+		t.Errorf("selected = %v, want the rest ordered by load: u3, u1, u5", got)
+	}
+	if cands[0].ID != "u1" || cands[4].ID != "u5" {
+		t.Errorf("candidates were reordered: %v", cands)
+	}
+}
+
+// TestCreatePRRollbackKeepsRotation проверяет, что CreatePR, откатившийся
+// после выбора ревьюеров, не сдвигает позицию round_robin.
+func TestCreatePRRollbackKeepsRotation(t *testing.T) {
+	ctx := context.Background()
+	teams := memory.NewTeamRepo()
+	svc := NewService(
+		memory.NewStore(), teams, memory.NewUserRepo(), memory.NewPRRepo(),
+		memory.NewCodeOwnersRepo(), memory.NewWebhookRepo(), memory.NewTokenRepo(),
+		newTestSelectors(t), domain.TeamSettings{ReviewerCount: 1, SLAAction: domain.SLAEscalate},
+	)
+
+	members := []domain.User{}
+	for _, id := range []string{"u1", "u2", "u3", "u4"} {
+		members = append(members, domain.User{ID: id, Username: id, IsActive: true})
+	}
+	if err := svc.CreateTeam(ctx, domain.Team{Name: "backend", Members: members}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ревьюеров меньше минимума: выбор сделан, но транзакция откатывается
+	err := teams.UpsertSettings(ctx, svc.db, domain.TeamSettings{
+		TeamName: "backend", ReviewerCount: 2, MinReviewers: 4, Strategy: string(StrategyRoundRobin), SLAAction: domain.SLAEscalate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.CreatePR(ctx, domain.PullRequest{ID: "pr-1", Name: "pr-1", AuthorID: "u1"})
+	if !errors.Is(err, ErrNotEnoughReviewers) {
+		t.Fatalf("CreatePR = %v, want ErrNotEnoughReviewers", err)
+	}
+
+	_, err = svc.UpdateTeamSettings(ctx, domain.TeamSettingsUpdate{TeamName: "backend", ReviewerCount: ptr(1), MinReviewers: ptr(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"u2", "u3", "u4", "u2"} {
+		id := fmt.Sprintf("pr-%d", i+2)
+		pr, err := svc.CreatePR(ctx, domain.PullRequest{ID: id, Name: id, AuthorID: "u1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := userIDs(pr.Reviewers); !slices.Equal(got, []string{want}) {
+			t.Errorf("PR %d reviewers = %v, want [%s]", i+1, got, want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
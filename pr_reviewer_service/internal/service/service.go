@@ -1,9 +1,13 @@
 package service
 
 import (
-	"database/sql"
+	"context"
 	"errors"
+	"pr-reviewer/internal/domain"
 	"pr-reviewer/internal/repository"
+	"pr-reviewer/internal/tracing"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -16,25 +20,74 @@ var (
 	ErrPRMerged       = errors.New("cannot reassign on merged PR")
 	ErrNotAssigned    = errors.New("reviewer is not assigned to this PR")
 	ErrNoCandidate    = errors.New("no active replacement candidate in team")
+
+	ErrNoOwnerAvailable = errors.New("no active code owner available for changed files")
+
+	ErrInvalidSettings    = errors.New("invalid team settings")
+	ErrNotEnoughReviewers = errors.New("not enough active reviewers in team")
+	ErrInvalidCodeOwners  = errors.New("invalid CODEOWNERS content")
+	ErrInvalidTags        = errors.New("invalid tags")
+	ErrInvalidAbsence     = errors.New("absence must end after it starts")
+	ErrAbsenceNotFound    = errors.New("absence not found")
+	ErrReviewerIsAuthor   = errors.New("author cannot review own PR")
+	ErrAlreadyAssigned    = errors.New("user is already assigned to this PR")
+	ErrReviewerInactive   = errors.New("user is not active or is absent")
+	ErrInvalidDecision    = errors.New("invalid review decision")
+	ErrMergePolicyUnmet   = errors.New("merge policy is not satisfied")
+	ErrPRNotOpen          = errors.New("pull request is not open")
+	ErrInvalidTransition  = errors.New("invalid pull request status transition")
+
+	ErrInvalidSubscription  = errors.New("invalid webhook subscription")
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+	ErrInvalidExternalAccount = errors.New("invalid external account")
+
+	ErrInvalidToken    = errors.New("invalid API token")
+	ErrTokenNotFound   = errors.New("API token not found or already revoked")
+	ErrUnauthenticated = errors.New("missing, unknown, expired or revoked API token")
+	ErrForbidden       = errors.New("insufficient permissions")
+	ErrNoActingUser    = errors.New("user is required: pass it explicitly or authenticate as a user")
 )
 
 type Service struct {
-	db        *sql.DB
-	repoTeams repository.TeamRepository
-	repoUsers repository.UserRepository
-	repoPR    repository.PullRequestRepository
+	db         repository.DB
+	repoTeams  repository.TeamRepository
+	repoUsers  repository.UserRepository
+	repoPR     repository.PullRequestRepository
+	repoOwners repository.CodeOwnersRepository
+	repoHooks  repository.WebhookRepository
+	repoTokens repository.TokenRepository
+	selectors  *TeamSelectors
+	// defaults — настройки назначения для команд без сохранённых настроек
+	defaults domain.TeamSettings
 }
 
 func NewService(
-	db *sql.DB,
+	db repository.DB,
 	repoTeams repository.TeamRepository,
 	repoUsers repository.UserRepository,
 	repoPR repository.PullRequestRepository,
+	repoOwners repository.CodeOwnersRepository,
+	repoHooks repository.WebhookRepository,
+	repoTokens repository.TokenRepository,
+	selectors *TeamSelectors,
+	defaults domain.TeamSettings,
 ) *Service {
 	return &Service{
-		db:        db,
-		repoTeams: repoTeams,
-		repoUsers: repoUsers,
-		repoPR:    repoPR,
+		db:         db,
+		repoTeams:  repoTeams,
+		repoUsers:  repoUsers,
+		repoPR:     repoPR,
+		repoOwners: repoOwners,
+		repoHooks:  repoHooks,
+		repoTokens: repoTokens,
+		selectors:  selectors,
+		defaults:   defaults,
 	}
 }
+
+// startSpan открывает спан метода сервиса; SQL-запросы внутри метода
+// попадают в него дочерними спанами драйвера.
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "pr-reviewer/internal/service", "Service."+method)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+func (s *Service) GetAssignments(ctx context.Context, prID string) ([]domain.ReviewAssignment, error) {
+	ctx, span := startSpan(ctx, "GetAssignments")
+	defer span.End()
+
+	exists, err := s.repoPR.Exists(ctx, s.db, prID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPRNotFound
+	}
+
+	return s.repoPR.GetAssignments(ctx, s.db, prID, s.defaultSLA())
+}
+
+// defaultSLA — SLA из конфигурации для команд без сохранённых настроек.
+func (s *Service) defaultSLA() domain.SLAPolicy {
+	sla := domain.SLAPolicy{Action: s.defaults.SLAAction}
+	if s.defaults.SLAHours != nil {
+		sla.Hours = *s.defaults.SLAHours
+	}
+	if sla.Action == "" {
+		sla.Action = domain.SLAEscalate
+	}
+	return sla
+}
+
+// ProcessOverdueReviews находит ревью, просроченные по SLA команды автора,
+// и эскалирует их либо передаёт другому ревьюеру по правилам ReassignReviewer.
+// Если замену найти не удалось, ревью эскалируется. Каждое назначение
+// обрабатывается в отдельной транзакции; ошибка по одному назначению
+// попадает в отчёт и не прерывает обработку остальных.
+func (s *Service) ProcessOverdueReviews(ctx context.Context) (*domain.SLAReport, error) {
+	ctx, span := startSpan(ctx, "ProcessOverdueReviews")
+	defer span.End()
+
+	overdue, err := s.repoPR.GetOverdueAssignments(ctx, s.db, s.defaultSLA())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.SLAReport{
+		Escalated:  []domain.ReviewAssignment{},
+		Reassigned: []domain.Reassignment{},
+		Failed:     []domain.ReassignmentFailure{},
+	}
+	for _, a := range overdue {
+		if err := s.handleOverdue(ctx, a, report); err != nil {
+			logging.FromContext(ctx).Error("Failed to process overdue review",
+				zap.String("pull_request_id", a.PullRequestID),
+				zap.String("reviewer_id", a.ReviewerID),
+				zap.Error(err),
+			)
+			report.Failed = append(report.Failed, domain.ReassignmentFailure{
+				PullRequestID: a.PullRequestID,
+				Reason:        err.Error(),
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) handleOverdue(ctx context.Context, a domain.ReviewAssignment, report *domain.SLAReport) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Назначение сначала забирается, поэтому реплики, нашедшие его одновременно,
+	// не эскалируют и не переназначают его повторно
+	claimed, err := s.repoPR.MarkEscalated(ctx, tx, a.PullRequestID, a.ReviewerID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	var noCandidate *domain.ReassignmentFailure
+	if a.SLAAction == domain.SLAReassign {
+		selectors := s.selectors.Begin()
+		_, newReviewer, err := s.reassignReviewer(ctx, tx, selectors, a.PullRequestID, a.ReviewerID)
+		switch {
+		case err == nil:
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+			selectors.Commit()
+			metrics.ReviewersReassigned.Inc()
+			report.Reassigned = append(report.Reassigned, domain.Reassignment{
+				PullRequestID: a.PullRequestID,
+				NewReviewerID: newReviewer.ID,
+			})
+			return nil
+		case errors.Is(err, ErrNoCandidate), errors.Is(err, ErrNoOwnerAvailable):
+			noCandidate = &domain.ReassignmentFailure{
+				PullRequestID: a.PullRequestID,
+				Reason:        err.Error(),
+			}
+		default:
+			return err
+		}
+	}
+
+	if err := s.publish(ctx, tx, domain.EventReviewSLAEscalate, a); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Warn("Review SLA exceeded",
+		zap.String("pull_request_id", a.PullRequestID),
+		zap.String("reviewer_id", a.ReviewerID),
+		zap.String("team", a.TeamName),
+		zap.Time("assigned_at", a.AssignedAt),
+		zap.Int("sla_hours", a.SLAHours),
+	)
+	if noCandidate != nil {
+		report.Failed = append(report.Failed, *noCandidate)
+	}
+	report.Escalated = append(report.Escalated, a)
+	return nil
+}
@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
 )
 
 func (s *Service) CreateTeam(ctx context.Context, team domain.Team) error {
-	tx, err := s.db.BeginTx(ctx, nil)
+	ctx, span := startSpan(ctx, "CreateTeam")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
@@ -24,10 +29,26 @@ func (s *Service) CreateTeam(ctx context.Context, team domain.Team) error {
 		return err
 	}
 
+	for _, m := range team.Members {
+		if m.Tags == nil {
+			continue
+		}
+		tags, err := normalizeTags(m.Tags)
+		if err != nil {
+			return err
+		}
+		if err := s.repoUsers.SetTags(ctx, tx, m.ID, tags); err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit()
 }
 
 func (s *Service) GetTeam(ctx context.Context, name string) (*domain.Team, error) {
+	ctx, span := startSpan(ctx, "GetTeam")
+	defer span.End()
+
 	team, err := s.repoTeams.GetByName(ctx, s.db, name)
 	if err != nil {
 		return nil, err
@@ -39,3 +60,181 @@ func (s *Service) GetTeam(ctx context.Context, name string) (*domain.Team, error
 
 	return team, nil
 }
+
+func (s *Service) GetTeamSettings(ctx context.Context, name string) (*domain.TeamSettings, error) {
+	ctx, span := startSpan(ctx, "GetTeamSettings")
+	defer span.End()
+
+	exists, err := s.repoTeams.Exists(ctx, s.db, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTeamNotFound
+	}
+
+	settings, err := s.teamSettings(ctx, s.db, name)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateTeamSettings применяет к сохранённым настройкам команды (или к значениям
+// по умолчанию, если их нет) только переданные поля update.
+func (s *Service) UpdateTeamSettings(ctx context.Context, update domain.TeamSettingsUpdate) (*domain.TeamSettings, error) {
+	ctx, span := startSpan(ctx, "UpdateTeamSettings")
+	defer span.End()
+
+	seen := make(map[string]bool, len(update.FallbackTeams))
+	for _, fallback := range update.FallbackTeams {
+		if fallback == update.TeamName || seen[fallback] {
+			return nil, fmt.Errorf("%w: duplicate or self-referencing fallback team %q", ErrInvalidSettings, fallback)
+		}
+		seen[fallback] = true
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	exists, err := s.repoTeams.Exists(ctx, tx, update.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTeamNotFound
+	}
+
+	// Стратегию по умолчанию не подставляем, чтобы не закрепить её за командой
+	stored, err := s.repoTeams.GetSettings(ctx, tx, update.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	settings := s.defaults
+	// SLA из конфигурации тоже не закрепляем: незаданные поля хранятся как NULL
+	settings.SLAHours, settings.SLAAction = nil, ""
+	if stored != nil {
+		settings = *stored
+	}
+	settings.TeamName = update.TeamName
+
+	if err := mergeTeamSettings(&settings, update); err != nil {
+		return nil, err
+	}
+
+	if err := s.repoTeams.UpsertSettings(ctx, tx, settings); err != nil {
+		return nil, err
+	}
+
+	if update.FallbackTeams != nil {
+		for _, fallback := range update.FallbackTeams {
+			exists, err := s.repoTeams.Exists(ctx, tx, fallback)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, fmt.Errorf("%w: fallback team %q not found", ErrInvalidSettings, fallback)
+			}
+		}
+		if err := s.repoTeams.SetFallbacks(ctx, tx, update.TeamName, update.FallbackTeams); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.teamSettings(ctx, tx, update.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// mergeTeamSettings переносит в settings заданные поля update и проверяет результат.
+func mergeTeamSettings(settings *domain.TeamSettings, update domain.TeamSettingsUpdate) error {
+	if update.Strategy != nil {
+		if *update.Strategy != "" {
+			if _, err := ParseStrategy(*update.Strategy); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+			}
+		}
+		settings.Strategy = *update.Strategy
+	}
+	if update.SLAAction != nil {
+		if *update.SLAAction != "" && !update.SLAAction.Valid() {
+			return fmt.Errorf("%w: sla_action must be escalate or reassign", ErrInvalidSettings)
+		}
+		settings.SLAAction = *update.SLAAction
+	}
+	if update.SLAHours != nil {
+		hours := *update.SLAHours
+		settings.SLAHours = &hours
+	}
+
+	setInt(&settings.ReviewerCount, update.ReviewerCount)
+	setInt(&settings.MinReviewers, update.MinReviewers)
+	setInt(&settings.RequiredApprovals, update.RequiredApprovals)
+	if update.ReassignOnDeactivate != nil {
+		settings.ReassignOnDeactivate = *update.ReassignOnDeactivate
+	}
+	if update.OwnerFallback != nil {
+		settings.OwnerFallback = *update.OwnerFallback
+	}
+	if update.BlockOnChangesRequested != nil {
+		settings.BlockOnChangesRequested = *update.BlockOnChangesRequested
+	}
+
+	if settings.ReviewerCount < 0 || settings.MinReviewers < 0 || settings.MinReviewers > settings.ReviewerCount {
+		return fmt.Errorf("%w: min_reviewers must be between 0 and reviewer_count, got %d and %d",
+			ErrInvalidSettings, settings.MinReviewers, settings.ReviewerCount)
+	}
+	if settings.RequiredApprovals < 0 {
+		return fmt.Errorf("%w: required_approvals must not be negative", ErrInvalidSettings)
+	}
+	if settings.SLAHours != nil && *settings.SLAHours < 0 {
+		return fmt.Errorf("%w: sla_hours must not be negative", ErrInvalidSettings)
+	}
+	return nil
+}
+
+func setInt(dst *int, value *int) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// teamSettings возвращает действующие настройки команды: сохранённые значения
+// или значения по умолчанию, с подставленной глобальной стратегией.
+func (s *Service) teamSettings(ctx context.Context, db repository.Querier, name string) (domain.TeamSettings, error) {
+	settings, err := s.repoTeams.GetSettings(ctx, db, name)
+	if err != nil {
+		return domain.TeamSettings{}, err
+	}
+	if settings == nil {
+		defaults := s.defaults
+		defaults.TeamName = name
+		settings = &defaults
+	}
+	if settings.Strategy == "" {
+		settings.Strategy = string(s.selectors.StrategyFor(name))
+	}
+	sla := s.defaultSLA()
+	if settings.SLAHours == nil {
+		settings.SLAHours = &sla.Hours
+	}
+	if settings.SLAAction == "" {
+		settings.SLAAction = sla.Action
+	}
+
+	settings.FallbackTeams, err = s.repoTeams.GetFallbacks(ctx, db, name)
+	if err != nil {
+		return domain.TeamSettings{}, err
+	}
+	return *settings, nil
+}
@@ -2,18 +2,160 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/metrics"
+	"pr-reviewer/internal/repository"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
 )
 
-func (s *Service) SetUserActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
-	user, err := s.repoUsers.SetIsActive(ctx, s.db, userID, isActive)
+// SetUserActive меняет флаг активности пользователя. При деактивации его открытые
+// ревью перераспределяются в той же транзакции, если это включено параметром
+// reassign или, когда он не задан, настройкой команды. Отчёт о перераспределении
+// возвращается только если оно выполнялось.
+func (s *Service) SetUserActive(ctx context.Context, userID string, isActive bool, reassign *bool) (*domain.User, *domain.ReassignmentReport, error) {
+	ctx, span := startSpan(ctx, "SetUserActive")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := s.repoUsers.SetIsActive(ctx, tx, userID, isActive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	var report *domain.ReassignmentReport
+	selectors := s.selectors.Begin()
+	if !isActive {
+		doReassign := false
+		if reassign != nil {
+			doReassign = *reassign
+		} else {
+			settings, err := s.teamSettings(ctx, tx, user.TeamName)
+			if err != nil {
+				return nil, nil, err
+			}
+			doReassign = settings.ReassignOnDeactivate
+		}
+
+		if doReassign {
+			report, err = s.reassignOpenReviews(ctx, tx, selectors, userID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	selectors.Commit()
+	if report != nil {
+		metrics.ReviewersReassigned.Add(float64(len(report.Reassigned)))
+		logging.FromContext(ctx).Info("Open reviews of deactivated user reassigned",
+			zap.String("user_id", userID),
+			zap.Int("reassigned", len(report.Reassigned)),
+			zap.Int("failed", len(report.Failed)),
+		)
+	}
+
+	return user, report, nil
+}
+
+// reassignOpenReviews передаёт все открытые ревью пользователя другим ревьюерам
+// по тем же правилам, что и ReassignReviewer. PR без подходящей замены
+// попадают в Failed и остаются за пользователем.
+func (s *Service) reassignOpenReviews(ctx context.Context, tx repository.Querier, selectors *TeamSelectors, userID string) (*domain.ReassignmentReport, error) {
+	prs, err := s.repoPR.GetByReviewerID(ctx, tx, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	report := &domain.ReassignmentReport{
+		Reassigned: []domain.Reassignment{},
+		Failed:     []domain.ReassignmentFailure{},
+	}
+	for _, pr := range prs {
+		_, newReviewer, err := s.reassignReviewer(ctx, tx, selectors, pr.ID, userID)
+		switch {
+		case err == nil:
+			report.Reassigned = append(report.Reassigned, domain.Reassignment{
+				PullRequestID: pr.ID,
+				NewReviewerID: newReviewer.ID,
+			})
+		case errors.Is(err, ErrNoCandidate), errors.Is(err, ErrNoOwnerAvailable):
+			report.Failed = append(report.Failed, domain.ReassignmentFailure{
+				PullRequestID: pr.ID,
+				Reason:        err.Error(),
+			})
+		default:
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) SetUserTags(ctx context.Context, userID string, tags []string) (*domain.User, error) {
+	ctx, span := startSpan(ctx, "SetUserTags")
+	defer span.End()
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := s.repoUsers.GetByID(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
 	if user == nil {
 		return nil, ErrUserNotFound
 	}
 
+	if err := s.repoUsers.SetTags(ctx, tx, userID, tags); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	user.Tags = tags
 	return user, nil
 }
+
+// normalizeTags приводит теги и метки к нижнему регистру и убирает дубликаты.
+func normalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > 64 || strings.ContainsAny(tag, ", \t") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTags, tag)
+		}
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository"
+	"time"
+)
+
+const deliveryHistoryLimit = 100
+
+// eventEnvelope — тело запроса, которое получает подписчик.
+type eventEnvelope struct {
+	Event      domain.EventType `json:"event"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       any              `json:"data"`
+}
+
+// publish записывает событие в outbox в транзакции изменения,
+// поэтому событие разошлётся тогда и только тогда, когда изменение закоммичено.
+func (s *Service) publish(ctx context.Context, tx repository.Querier, eventType domain.EventType, data any) error {
+	payload, err := json.Marshal(eventEnvelope{
+		Event:      eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return s.repoHooks.AddEvent(ctx, tx, eventType, payload)
+}
+
+func pullRequestEvent(pr *domain.PullRequest) domain.PullRequestEvent {
+	return domain.PullRequestEvent{
+		PullRequestID: pr.ID,
+		Name:          pr.Name,
+		AuthorID:      pr.AuthorID,
+		Status:        pr.Status,
+		Reviewers:     reviewerIDs(pr.Reviewers),
+		MergedAt:      pr.MergedAt,
+	}
+}
+
+func (s *Service) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "CreateWebhookSubscription")
+	defer span.End()
+
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidSubscription)
+	}
+	if sub.Secret == "" {
+		return nil, fmt.Errorf("%w: secret is required", ErrInvalidSubscription)
+	}
+	for _, e := range sub.Events {
+		if !e.Valid() {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, e)
+		}
+	}
+
+	return s.repoHooks.CreateSubscription(ctx, s.db, sub)
+}
+
+func (s *Service) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "ListWebhookSubscriptions")
+	defer span.End()
+
+	return s.repoHooks.ListSubscriptions(ctx, s.db)
+}
+
+func (s *Service) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "DeleteWebhookSubscription")
+	defer span.End()
+
+	deleted, err := s.repoHooks.DeleteSubscription(ctx, s.db, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// GetWebhookDeliveries возвращает последние доставки; subscriptionID = 0 — по всем подпискам.
+func (s *Service) GetWebhookDeliveries(ctx context.Context, subscriptionID int64) ([]domain.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "GetWebhookDeliveries")
+	defer span.End()
+
+	return s.repoHooks.ListDeliveries(ctx, s.db, subscriptionID, deliveryHistoryLimit)
+}
+
+// FanOutWebhookEvents превращает до limit событий outbox в доставки подписчикам.
+func (s *Service) FanOutWebhookEvents(ctx context.Context, limit int) (int, error) {
+	ctx, span := startSpan(ctx, "FanOutWebhookEvents")
+	defer span.End()
+
+	return s.repoHooks.FanOutEvents(ctx, s.db, limit)
+}
+
+// ClaimWebhookDeliveries берёт в работу до limit доставок, которые пора отправить.
+// Пока доставка не завершена, она не будет выдана повторно в течение lease.
+func (s *Service) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "ClaimWebhookDeliveries")
+	defer span.End()
+
+	return s.repoHooks.ClaimDeliveries(ctx, s.db, limit, lease)
+}
+
+func (s *Service) CompleteWebhookDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	ctx, span := startSpan(ctx, "CompleteWebhookDelivery")
+	defer span.End()
+
+	return s.repoHooks.CompleteDelivery(ctx, s.db, delivery)
+}
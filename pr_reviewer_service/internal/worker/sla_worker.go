@@ -0,0 +1,61 @@
+// Package worker содержит фоновые задачи, запускаемые вместе с HTTP-сервером.
+package worker
+
+import (
+	"context"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/service"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SLAWorker периодически проверяет просроченные по SLA ревью.
+type SLAWorker struct {
+	svc      *service.Service
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewSLAWorker(svc *service.Service, interval time.Duration, logger *zap.Logger) *SLAWorker {
+	return &SLAWorker{
+		svc:      svc,
+		interval: interval,
+		logger:   logger.With(zap.String("worker", "sla")),
+	}
+}
+
+// Run работает до отмены ctx. Начатая проверка доводится до конца,
+// чтобы остановка сервиса не обрывала транзакции.
+func (w *SLAWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.tick(context.WithoutCancel(ctx))
+		}
+	}
+}
+
+func (w *SLAWorker) tick(ctx context.Context) {
+	ctx = logging.WithContext(ctx, w.logger)
+	report, err := w.svc.ProcessOverdueReviews(ctx)
+	if err != nil {
+		w.logger.Error("Failed to process overdue reviews", zap.Error(err))
+	}
+	if report == nil {
+		return
+	}
+
+	if len(report.Escalated) > 0 || len(report.Reassigned) > 0 {
+		w.logger.Info("Processed overdue reviews",
+			zap.Int("escalated", len(report.Escalated)),
+			zap.Int("reassigned", len(report.Reassigned)),
+			zap.Int("failed", len(report.Failed)),
+		)
+	}
+}
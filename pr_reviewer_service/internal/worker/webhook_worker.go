@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/logging"
+	"pr-reviewer/internal/service"
+	"pr-reviewer/internal/webhook"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	webhookBatchSize  = 50
+	webhookBaseDelay  = 10 * time.Second
+	webhookMaxBackoff = time.Hour
+)
+
+// WebhookWorker рассылает события outbox подписчикам и повторяет
+// неудачные доставки с экспоненциальной задержкой.
+type WebhookWorker struct {
+	svc         *service.Service
+	sender      *webhook.Sender
+	interval    time.Duration
+	timeout     time.Duration
+	maxAttempts int
+	logger      *zap.Logger
+}
+
+func NewWebhookWorker(svc *service.Service, interval, timeout time.Duration, maxAttempts int, logger *zap.Logger) *WebhookWorker {
+	return &WebhookWorker{
+		svc:         svc,
+		sender:      webhook.NewSender(timeout),
+		interval:    interval,
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+		logger:      logger.With(zap.String("worker", "webhook")),
+	}
+}
+
+// Run работает до отмены ctx. После отмены начатая доставка завершается,
+// а оставшиеся взятые в работу доставки повторятся после истечения аренды.
+func (w *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.tick(ctx)
+		}
+	}
+}
+
+func (w *WebhookWorker) tick(ctx context.Context) {
+	work := logging.WithContext(context.WithoutCancel(ctx), w.logger)
+	if _, err := w.svc.FanOutWebhookEvents(work, webhookBatchSize); err != nil {
+		w.logger.Error("Failed to fan out webhook events", zap.Error(err))
+	}
+
+	// Аренда с запасом покрывает таймаут запроса, чтобы доставку не взял другой экземпляр
+	deliveries, err := w.svc.ClaimWebhookDeliveries(work, webhookBatchSize, 2*w.timeout)
+	if err != nil {
+		w.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	for _, d := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		w.deliver(work, d)
+	}
+}
+
+func (w *WebhookWorker) deliver(ctx context.Context, d domain.WebhookDelivery) {
+	code, sendErr := w.sender.Send(ctx, d)
+	now := time.Now()
+
+	if code != 0 {
+		d.ResponseStatus = &code
+	}
+	switch {
+	case sendErr == nil:
+		d.Status = domain.DeliveryDelivered
+		d.DeliveredAt = &now
+		d.LastError = nil
+	case d.Attempts >= w.maxAttempts:
+		d.Status = domain.DeliveryFailed
+		msg := sendErr.Error()
+		d.LastError = &msg
+	default:
+		d.NextAttemptAt = now.Add(backoff(d.Attempts))
+		msg := sendErr.Error()
+		d.LastError = &msg
+	}
+
+	if err := w.svc.CompleteWebhookDelivery(ctx, d); err != nil {
+		w.logger.Error("Failed to save webhook delivery result", zap.Int64("delivery_id", d.ID), zap.Error(err))
+		return
+	}
+	if sendErr != nil {
+		w.logger.Warn("Webhook delivery failed",
+			zap.Int64("delivery_id", d.ID),
+			zap.String("url", d.URL),
+			zap.Int("attempt", d.Attempts),
+			zap.String("status", string(d.Status)),
+			zap.Error(sendErr),
+		)
+	}
+}
+
+// backoff возвращает задержку перед следующей попыткой: 10s, 20s, 40s... но не больше часа.
+func backoff(attempts int) time.Duration {
+	delay := webhookBaseDelay
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff)
+}
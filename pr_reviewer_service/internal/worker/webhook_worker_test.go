@@ -0,0 +1,334 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"pr-reviewer/internal/domain"
+	"pr-reviewer/internal/repository/memory"
+	"pr-reviewer/internal/service"
+	"pr-reviewer/internal/webhook"
+
+	"go.uber.org/zap"
+)
+
+const testSecret = "s3cret"
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// subscriber — подписчик вебхуков, который отвечает кодами из statuses
+// по очереди, а после них — 200.
+type subscriber struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func newSubscriber(t *testing.T, statuses ...int) *subscriber {
+	s := &subscriber{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, receivedRequest{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *subscriber) received() []receivedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedRequest(nil), s.requests...)
+}
+
+func newTestService(t *testing.T) *service.Service {
+	t.Helper()
+	selectors, err := service.NewTeamSelectors("random", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service.NewService(
+		memory.NewStore(),
+		memory.NewTeamRepo(),
+		memory.NewUserRepo(),
+		memory.NewPRRepo(),
+		memory.NewCodeOwnersRepo(),
+		memory.NewWebhookRepo(),
+		memory.NewTokenRepo(),
+		selectors,
+		domain.TeamSettings{ReviewerCount: 2, SLAAction: domain.SLAEscalate},
+	)
+}
+
+// setup подписывает sub на events и создаёт команду из трёх активных пользователей.
+func setup(t *testing.T, svc *service.Service, sub *subscriber, events ...domain.EventType) *domain.WebhookSubscription {
+	t.Helper()
+	ctx := context.Background()
+
+	created, err := svc.CreateWebhookSubscription(ctx, domain.WebhookSubscription{
+		URL:      sub.URL,
+		Secret:   testSecret,
+		Events:   events,
+		IsActive: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = svc.CreateTeam(ctx, domain.Team{Name: "backend", Members: []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carol", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+func createPR(t *testing.T, svc *service.Service, id string, status domain.PRStatus) {
+	t.Helper()
+	_, err := svc.CreatePR(context.Background(), domain.PullRequest{ID: id, Name: id, AuthorID: "u1", Status: status})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestWorker(svc *service.Service, maxAttempts int) *WebhookWorker {
+	return NewWebhookWorker(svc, time.Second, time.Second, maxAttempts, zap.NewNop())
+}
+
+func delivery(t *testing.T, svc *service.Service, subID int64) domain.WebhookDelivery {
+	t.Helper()
+	deliveries, err := svc.GetWebhookDeliveries(context.Background(), subID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func TestWebhookDeliverySignedRequest(t *testing.T) {
+	svc := newTestService(t)
+	sub := newSubscriber(t)
+	created := setup(t, svc, sub, domain.EventPRCreated)
+	createPR(t, svc, "pr-1", "")
+
+	newTestWorker(svc, 3).tick(context.Background())
+
+	requests := sub.received()
+	if len(requests) != 1 {
+		t.Fatalf("subscriber got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if !webhook.Verify(testSecret, req.body, req.header.Get(webhook.HeaderSignature)) {
+		t.Errorf("signature %q does not match body", req.header.Get(webhook.HeaderSignature))
+	}
+	if webhook.Verify("other", req.body, req.header.Get(webhook.HeaderSignature)) {
+		t.Error("signature matches a different secret")
+	}
+	if got := req.header.Get(webhook.HeaderEvent); got != string(domain.EventPRCreated) {
+		t.Errorf("event header = %q, want %q", got, domain.EventPRCreated)
+	}
+
+	d := delivery(t, svc, created.ID)
+	if got := req.header.Get(webhook.HeaderDelivery); got != strconv.FormatInt(d.ID, 10) {
+		t.Errorf("delivery header = %q, want %d", got, d.ID)
+	}
+	if d.Status != domain.DeliveryDelivered || d.Attempts != 1 || d.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want delivered on the first attempt", d)
+	}
+
+	var envelope struct {
+		Event domain.EventType        `json:"event"`
+		Data  domain.PullRequestEvent `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Event != domain.EventPRCreated || envelope.Data.PullRequestID != "pr-1" || len(envelope.Data.Reviewers) != 2 {
+		t.Errorf("payload = %+v", envelope)
+	}
+}
+
+func TestWebhookDeliveryRetriesWithBackoff(t *testing.T) {
+	svc := newTestService(t)
+	sub := newSubscriber(t, http.StatusInternalServerError)
+	created := setup(t, svc, sub)
+	createPR(t, svc, "pr-1", "")
+	w := newTestWorker(svc, 3)
+	ctx := context.Background()
+
+	before := time.Now()
+	w.tick(ctx)
+
+	d := delivery(t, svc, created.ID)
+	if d.Status != domain.DeliveryPending || d.Attempts != 1 {
+		t.Fatalf("delivery = %+v, want pending after the first attempt", d)
+	}
+	if d.ResponseStatus == nil || *d.ResponseStatus != http.StatusInternalServerError || d.LastError == nil {
+		t.Errorf("delivery = %+v, want response status 500 and an error", d)
+	}
+	if d.NextAttemptAt.Before(before.Add(webhookBaseDelay)) || d.NextAttemptAt.After(time.Now().Add(webhookBaseDelay)) {
+		t.Errorf("next attempt at %v, want %v after the failure", d.NextAttemptAt, webhookBaseDelay)
+	}
+
+	// До наступления следующей попытки доставка не отправляется
+	w.tick(ctx)
+	if n := len(sub.received()); n != 1 {
+		t.Fatalf("subscriber got %d requests before backoff elapsed, want 1", n)
+	}
+
+	d.NextAttemptAt = time.Now()
+	if err := svc.CompleteWebhookDelivery(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	w.tick(ctx)
+
+	requests := sub.received()
+	if len(requests) != 2 {
+		t.Fatalf("subscriber got %d requests, want 2", len(requests))
+	}
+	if got, want := requests[1].header.Get(webhook.HeaderDelivery), requests[0].header.Get(webhook.HeaderDelivery); got != want {
+		t.Errorf("retry delivery id = %s, want %s", got, want)
+	}
+	d = delivery(t, svc, created.ID)
+	if d.Status != domain.DeliveryDelivered || d.Attempts != 2 || d.LastError != nil {
+		t.Errorf("delivery = %+v, want delivered on the second attempt", d)
+	}
+}
+
+func TestWebhookDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	svc := newTestService(t)
+	sub := newSubscriber(t, http.StatusBadGateway)
+	created := setup(t, svc, sub)
+	createPR(t, svc, "pr-1", "")
+
+	newTestWorker(svc, 1).tick(context.Background())
+
+	d := delivery(t, svc, created.ID)
+	if d.Status != domain.DeliveryFailed || d.Attempts != 1 || d.LastError == nil {
+		t.Errorf("delivery = %+v, want failed after the only attempt", d)
+	}
+}
+
+func TestClaimedDeliveryIsLeased(t *testing.T) {
+	svc := newTestService(t)
+	sub := newSubscriber(t)
+	setup(t, svc, sub)
+	createPR(t, svc, "pr-1", "")
+	ctx := context.Background()
+
+	if _, err := svc.FanOutWebhookEvents(ctx, webhookBatchSize); err != nil {
+		t.Fatal(err)
+	}
+	const lease = 50 * time.Millisecond
+	first, err := svc.ClaimWebhookDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 || first[0].URL != sub.URL || first[0].Secret != testSecret || len(first[0].Payload) == 0 {
+		t.Fatalf("claimed %+v, want one delivery with url, secret and payload", first)
+	}
+
+	// Пока аренда действует, другой экземпляр доставку не получит
+	again, err := svc.ClaimWebhookDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("claimed %d leased deliveries, want 0", len(again))
+	}
+
+	// Незавершённая доставка после истечения аренды выдаётся снова как новая попытка
+	time.Sleep(2 * lease)
+	expired, err := svc.ClaimWebhookDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].ID != first[0].ID || expired[0].Attempts != 2 {
+		t.Fatalf("claimed %+v after lease expiry, want delivery %d on attempt 2", expired, first[0].ID)
+	}
+}
+
+func TestLifecycleEventsAreDelivered(t *testing.T) {
+	svc := newTestService(t)
+	sub := newSubscriber(t)
+	setup(t, svc, sub, domain.EventPRReady, domain.EventPRClosed, domain.EventPRReopened)
+	ctx := context.Background()
+
+	createPR(t, svc, "pr-1", domain.PRStatusDraft)
+	if _, err := svc.MarkReady(ctx, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ClosePR(ctx, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ReopenPR(ctx, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	newTestWorker(svc, 3).tick(ctx)
+
+	want := []domain.EventType{domain.EventPRReady, domain.EventPRClosed, domain.EventPRReopened}
+	requests := sub.received()
+	if len(requests) != len(want) {
+		t.Fatalf("subscriber got %d requests, want %d", len(requests), len(want))
+	}
+	for i, req := range requests {
+		var envelope struct {
+			Event domain.EventType        `json:"event"`
+			Data  domain.PullRequestEvent `json:"data"`
+		}
+		if err := json.Unmarshal(req.body, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		if envelope.Event != want[i] {
+			t.Errorf("event %d = %s, want %s", i, envelope.Event, want[i])
+		}
+		if len(envelope.Data.Reviewers) != 2 {
+			t.Errorf("%s carries reviewers %v, want the 2 assigned on ready", envelope.Event, envelope.Data.Reviewers)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE team_settings (
+    team_name VARCHAR(255) PRIMARY KEY,
+    reviewer_count INTEGER NOT NULL DEFAULT 2,
+    min_reviewers INTEGER NOT NULL DEFAULT 0,
+    strategy VARCHAR(32),
+
+    CONSTRAINT fk_team_settings_team FOREIGN KEY (team_name)
+        REFERENCES teams(name) ON DELETE CASCADE,
+    CONSTRAINT chk_team_settings_counts
+        CHECK (reviewer_count >= 0 AND min_reviewers >= 0 AND min_reviewers <= reviewer_count)
+);
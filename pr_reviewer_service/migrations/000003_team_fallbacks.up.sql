@@ -0,0 +1,12 @@
+CREATE TABLE team_fallbacks (
+    team_name VARCHAR(255) NOT NULL,
+    fallback_team_name VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team_name),
+
+    CONSTRAINT fk_team_fallbacks_team FOREIGN KEY (team_name)
+        REFERENCES teams(name) ON DELETE CASCADE,
+    CONSTRAINT fk_team_fallbacks_fallback FOREIGN KEY (fallback_team_name)
+        REFERENCES teams(name) ON DELETE CASCADE,
+    CONSTRAINT chk_team_fallbacks_self CHECK (team_name <> fallback_team_name)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pull_request_files;
+DROP TABLE IF EXISTS code_owner_rule_owners;
+DROP TABLE IF EXISTS code_owner_rules;
@@ -0,0 +1,25 @@
+CREATE TABLE code_owner_rules (
+    id SERIAL PRIMARY KEY,
+    position INTEGER NOT NULL UNIQUE,
+    pattern VARCHAR(1024) NOT NULL
+);
+
+CREATE TABLE code_owner_rule_owners (
+    rule_id INTEGER NOT NULL,
+    owner_type VARCHAR(8) NOT NULL,
+    owner_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (rule_id, owner_type, owner_id),
+
+    CONSTRAINT fk_code_owner_rule FOREIGN KEY (rule_id)
+        REFERENCES code_owner_rules(id) ON DELETE CASCADE,
+    CONSTRAINT chk_code_owner_type CHECK (owner_type IN ('user', 'team'))
+);
+
+CREATE TABLE pull_request_files (
+    pull_request_id VARCHAR(255) NOT NULL,
+    path VARCHAR(1024) NOT NULL,
+    PRIMARY KEY (pull_request_id, path),
+
+    CONSTRAINT fk_pr_files_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(id) ON DELETE CASCADE
+);
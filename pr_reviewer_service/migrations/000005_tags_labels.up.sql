@@ -0,0 +1,17 @@
+CREATE TABLE user_tags (
+    user_id VARCHAR(255) NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (user_id, tag),
+
+    CONSTRAINT fk_user_tags_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE pull_request_labels (
+    pull_request_id VARCHAR(255) NOT NULL,
+    label VARCHAR(64) NOT NULL,
+    PRIMARY KEY (pull_request_id, label),
+
+    CONSTRAINT fk_pr_labels_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE team_settings ADD COLUMN reassign_on_deactivate BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS block_on_changes_requested,
+    DROP COLUMN IF EXISTS required_approvals;
+
+DROP TABLE IF EXISTS merge_overrides;
+DROP TABLE IF EXISTS pull_request_reviews;
+
+DROP TYPE IF EXISTS review_decision;
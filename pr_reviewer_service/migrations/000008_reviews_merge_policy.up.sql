@@ -0,0 +1,32 @@
+CREATE TYPE review_decision AS ENUM ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED');
+
+CREATE TABLE pull_request_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL,
+    reviewer_id VARCHAR(255) NOT NULL,
+    decision review_decision NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_pr_reviews_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pr_reviews_user FOREIGN KEY (reviewer_id)
+        REFERENCES users(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_pr_reviews_pr ON pull_request_reviews(pull_request_id, created_at);
+
+CREATE TABLE merge_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL,
+    forced_by VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    unmet_policy TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_merge_overrides_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(id) ON DELETE CASCADE
+);
+
+ALTER TABLE team_settings
+    ADD COLUMN required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0),
+    ADD COLUMN block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS closed_at;
+
+-- Значения из enum удалить нельзя, поэтому тип пересоздаётся.
+-- Черновики и закрытые PR при откате становятся открытыми.
+ALTER TABLE pull_requests ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE pull_requests ALTER COLUMN status TYPE VARCHAR(16);
+UPDATE pull_requests SET status = 'OPEN' WHERE status IN ('DRAFT', 'CLOSED');
+
+DROP TYPE pr_status;
+CREATE TYPE pr_status AS ENUM ('OPEN', 'MERGED');
+
+ALTER TABLE pull_requests ALTER COLUMN status TYPE pr_status USING status::pr_status;
+ALTER TABLE pull_requests ALTER COLUMN status SET DEFAULT 'OPEN';
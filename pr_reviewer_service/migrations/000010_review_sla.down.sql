@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_pr_reviewers_pending;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS sla_action,
+    DROP COLUMN IF EXISTS sla_hours;
+
+ALTER TABLE pull_requests_reviewers
+    DROP COLUMN IF EXISTS escalated_at,
+    DROP COLUMN IF EXISTS first_action_at,
+    DROP COLUMN IF EXISTS assigned_at;
@@ -0,0 +1,12 @@
+ALTER TABLE pull_requests_reviewers
+    ADD COLUMN assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN first_action_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN escalated_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE team_settings
+    ADD COLUMN sla_hours INTEGER NOT NULL DEFAULT 0 CHECK (sla_hours >= 0),
+    ADD COLUMN sla_action VARCHAR(16) NOT NULL DEFAULT 'escalate'
+        CHECK (sla_action IN ('escalate', 'reassign'));
+
+CREATE INDEX idx_pr_reviewers_pending ON pull_requests_reviewers(assigned_at)
+    WHERE first_action_at IS NULL AND escalated_at IS NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE user_external_accounts (
+    provider VARCHAR(32) NOT NULL,
+    login VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (provider, login),
+    UNIQUE (provider, user_id),
+
+    CONSTRAINT fk_user_external_accounts_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
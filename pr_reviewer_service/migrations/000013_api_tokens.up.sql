@@ -0,0 +1,22 @@
+CREATE TABLE api_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- SHA-256 токена в hex, сам токен не хранится
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(32) NOT NULL,
+    team_name VARCHAR(255),
+    user_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT fk_api_tokens_team FOREIGN KEY (team_name)
+        REFERENCES teams(name) ON DELETE CASCADE,
+    CONSTRAINT fk_api_tokens_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_api_tokens_role CHECK (role IN ('admin', 'team_maintainer', 'user', 'service')),
+    CONSTRAINT chk_api_tokens_scope CHECK (
+        (team_name IS NOT NULL) = (role = 'team_maintainer')
+        AND (user_id IS NOT NULL) = (role = 'user')
+    )
+);
@@ -0,0 +1 @@
+ALTER TABLE team_settings DROP COLUMN IF EXISTS owner_fallback;
@@ -0,0 +1 @@
+ALTER TABLE team_settings ADD COLUMN owner_fallback BOOLEAN NOT NULL DEFAULT FALSE;
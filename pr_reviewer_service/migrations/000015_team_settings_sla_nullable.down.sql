@@ -0,0 +1,8 @@
+UPDATE team_settings
+SET sla_hours = COALESCE(sla_hours, 0), sla_action = COALESCE(sla_action, 'escalate');
+
+ALTER TABLE team_settings
+    ALTER COLUMN sla_hours SET DEFAULT 0,
+    ALTER COLUMN sla_hours SET NOT NULL,
+    ALTER COLUMN sla_action SET DEFAULT 'escalate',
+    ALTER COLUMN sla_action SET NOT NULL;
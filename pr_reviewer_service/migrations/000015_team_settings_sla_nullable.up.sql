@@ -0,0 +1,11 @@
+-- NULL в sla_hours и sla_action означает значение из конфигурации.
+ALTER TABLE team_settings
+    ALTER COLUMN sla_hours DROP DEFAULT,
+    ALTER COLUMN sla_hours DROP NOT NULL,
+    ALTER COLUMN sla_action DROP DEFAULT,
+    ALTER COLUMN sla_action DROP NOT NULL;
+
+-- Прежние значения по умолчанию не отличить от заданных явно: считаем их незаданными
+UPDATE team_settings
+SET sla_hours = NULL, sla_action = NULL
+WHERE sla_hours = 0 AND sla_action = 'escalate';
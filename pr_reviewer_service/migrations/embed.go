@@ -0,0 +1,15 @@
+// Package migrations встраивает SQL-миграции схемы в бинарник сервиса.
+package migrations
+
+import "embed"
+
+// FS содержит файлы NNNNNN_name.up.sql и NNNNNN_name.down.sql в формате golang-migrate.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLiteFS содержит схему SQLite: файлы sqlite/NNNN_name.sql применяются по
+// порядку номеров, номер последнего применённого хранится в PRAGMA user_version.
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
@@ -0,0 +1,193 @@
+-- Схема SQLite, равная схеме PostgreSQL после миграции 000012.
+-- Перечисления pr_status и review_decision заменены ограничениями CHECK,
+-- время хранится текстом в UTC и заполняется сервисом.
+
+CREATE TABLE teams (
+    name TEXT PRIMARY KEY
+);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    team_name TEXT NOT NULL
+        REFERENCES teams(name) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_users_team_name ON users(team_name);
+
+CREATE TABLE pull_requests (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT NOT NULL
+        REFERENCES users(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'OPEN'
+        CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
+    created_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_pull_requests_author_id ON pull_requests(author_id);
+
+CREATE TABLE pull_requests_reviewers (
+    pull_request_id TEXT NOT NULL
+        REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL
+        REFERENCES users(id) ON DELETE RESTRICT,
+    assigned_at TIMESTAMP NOT NULL,
+    first_action_at TIMESTAMP,
+    escalated_at TIMESTAMP,
+    PRIMARY KEY (pull_request_id, reviewer_id)
+);
+
+CREATE INDEX idx_pr_reviewers_reviewer_id ON pull_requests_reviewers(reviewer_id);
+CREATE INDEX idx_pr_reviewers_pending ON pull_requests_reviewers(assigned_at)
+    WHERE first_action_at IS NULL AND escalated_at IS NULL;
+
+CREATE TABLE team_settings (
+    team_name TEXT PRIMARY KEY
+        REFERENCES teams(name) ON DELETE CASCADE,
+    reviewer_count INTEGER NOT NULL DEFAULT 2,
+    min_reviewers INTEGER NOT NULL DEFAULT 0,
+    strategy TEXT,
+    reassign_on_deactivate BOOLEAN NOT NULL DEFAULT FALSE,
+    required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0),
+    block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    sla_hours INTEGER NOT NULL DEFAULT 0 CHECK (sla_hours >= 0),
+    sla_action TEXT NOT NULL DEFAULT 'escalate'
+        CHECK (sla_action IN ('escalate', 'reassign')),
+
+    CHECK (reviewer_count >= 0 AND min_reviewers >= 0 AND min_reviewers <= reviewer_count)
+);
+
+CREATE TABLE team_fallbacks (
+    team_name TEXT NOT NULL
+        REFERENCES teams(name) ON DELETE CASCADE,
+    fallback_team_name TEXT NOT NULL
+        REFERENCES teams(name) ON DELETE CASCADE,
+    priority INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team_name),
+
+    CHECK (team_name <> fallback_team_name)
+);
+
+CREATE TABLE code_owner_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    position INTEGER NOT NULL UNIQUE,
+    pattern TEXT NOT NULL
+);
+
+CREATE TABLE code_owner_rule_owners (
+    rule_id INTEGER NOT NULL
+        REFERENCES code_owner_rules(id) ON DELETE CASCADE,
+    owner_type TEXT NOT NULL CHECK (owner_type IN ('user', 'team')),
+    owner_id TEXT NOT NULL,
+    PRIMARY KEY (rule_id, owner_type, owner_id)
+);
+
+CREATE TABLE pull_request_files (
+    pull_request_id TEXT NOT NULL
+        REFERENCES pull_requests(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, path)
+);
+
+CREATE TABLE user_tags (
+    user_id TEXT NOT NULL
+        REFERENCES users(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (user_id, tag)
+);
+
+CREATE TABLE pull_request_labels (
+    pull_request_id TEXT NOT NULL
+        REFERENCES pull_requests(id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, label)
+);
+
+CREATE TABLE user_absences (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL
+        REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_user_absences_user_period ON user_absences(user_id, starts_at, ends_at);
+
+CREATE TABLE pull_request_reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL
+        REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL
+        REFERENCES users(id) ON DELETE RESTRICT,
+    decision TEXT NOT NULL
+        CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_pr_reviews_pr ON pull_request_reviews(pull_request_id, created_at);
+
+CREATE TABLE merge_overrides (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL
+        REFERENCES pull_requests(id) ON DELETE CASCADE,
+    forced_by TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    unmet_policy TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webhook_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    -- Типы событий через запятую; пустая строка — все события
+    events TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    dispatched_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL
+        REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id INTEGER NOT NULL
+        REFERENCES outbox_events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    response_status INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE user_external_accounts (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL
+        REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, login),
+    UNIQUE (provider, user_id)
+);
@@ -0,0 +1,21 @@
+-- Соответствует миграции PostgreSQL 000013.
+
+CREATE TABLE api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    -- SHA-256 токена в hex, сам токен не хранится
+    token_hash TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL
+        CHECK (role IN ('admin', 'team_maintainer', 'user', 'service')),
+    team_name TEXT
+        REFERENCES teams(name) ON DELETE CASCADE,
+    user_id TEXT
+        REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    CHECK (
+        (team_name IS NOT NULL) = (role = 'team_maintainer')
+        AND (user_id IS NOT NULL) = (role = 'user')
+    )
+);
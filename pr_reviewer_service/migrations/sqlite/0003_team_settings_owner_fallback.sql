@@ -0,0 +1,3 @@
+-- Соответствует миграции PostgreSQL 000014.
+
+ALTER TABLE team_settings ADD COLUMN owner_fallback BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,31 @@
+-- Соответствует миграции PostgreSQL 000015. SQLite не меняет ограничения
+-- столбцов, поэтому таблица пересоздаётся.
+
+CREATE TABLE team_settings_new (
+    team_name TEXT PRIMARY KEY
+        REFERENCES teams(name) ON DELETE CASCADE,
+    reviewer_count INTEGER NOT NULL DEFAULT 2,
+    min_reviewers INTEGER NOT NULL DEFAULT 0,
+    strategy TEXT,
+    reassign_on_deactivate BOOLEAN NOT NULL DEFAULT FALSE,
+    required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0),
+    block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    -- NULL означает значение из конфигурации
+    sla_hours INTEGER CHECK (sla_hours >= 0),
+    sla_action TEXT CHECK (sla_action IN ('escalate', 'reassign')),
+    owner_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+
+    CHECK (reviewer_count >= 0 AND min_reviewers >= 0 AND min_reviewers <= reviewer_count)
+);
+
+-- Прежние значения по умолчанию не отличить от заданных явно: считаем их незаданными
+INSERT INTO team_settings_new
+SELECT team_name, reviewer_count, min_reviewers, strategy, reassign_on_deactivate,
+    required_approvals, block_on_changes_requested,
+    CASE WHEN sla_hours = 0 AND sla_action = 'escalate' THEN NULL ELSE sla_hours END,
+    CASE WHEN sla_hours = 0 AND sla_action = 'escalate' THEN NULL ELSE sla_action END,
+    owner_fallback
+FROM team_settings;
+
+DROP TABLE team_settings;
+ALTER TABLE team_settings_new RENAME TO team_settings;